@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"dmr-models-convert/pkg/converter"
+
+	"github.com/spf13/cobra"
+)
+
+var diffFormat string
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <old.json> <new.json>",
+	Short: "Compare two converted Ollama model snapshots",
+	Long: `Compare two Ollama JSON files produced by convert and print the models
+that were added, removed, or changed. Like diff, exits with status 1 when
+differences are found and 2 on errors.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		before, err := loadOllamaResponse(args[0])
+		if err != nil {
+			return exitCodeError{2, fmt.Errorf("reading %s: %w", args[0], err)}
+		}
+
+		after, err := loadOllamaResponse(args[1])
+		if err != nil {
+			return exitCodeError{2, fmt.Errorf("reading %s: %w", args[1], err)}
+		}
+
+		diff := converter.DiffResponses(before, after)
+
+		err = printDiff(os.Stdout, diff, diffFormat)
+		if err != nil {
+			return exitCodeError{2, fmt.Errorf("printing diff: %w", err)}
+		}
+
+		if !diff.Empty() {
+			return exitCodeError{code: 1}
+		}
+		return nil
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffFormat, "format", "text", "Output format: text or json")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+// loadOllamaResponse reads an Ollama response from a JSON file
+func loadOllamaResponse(filename string) (converter.OllamaResponse, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return converter.OllamaResponse{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var response converter.OllamaResponse
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return converter.OllamaResponse{}, fmt.Errorf("failed to parse Ollama JSON: %w", err)
+	}
+
+	return response, nil
+}
+
+// printDiff writes the diff in the requested format
+func printDiff(w io.Writer, diff converter.ResponseDiff, format string) error {
+	switch format {
+	case "json":
+		jsonData, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(jsonData))
+	case "text":
+		for _, model := range diff.Added {
+			fmt.Fprintf(w, "+ %s (%s)\n", model.Name, model.Digest)
+		}
+		for _, model := range diff.Removed {
+			fmt.Fprintf(w, "- %s (%s)\n", model.Name, model.Digest)
+		}
+		for _, changed := range diff.Changed {
+			for _, change := range changed.Changes {
+				fmt.Fprintf(w, "~ %s: %s %s -> %s\n", changed.Name, change.Field, change.Old, change.New)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dmr-models-convert/pkg/converter"
+)
+
+func TestLoadOllamaResponse(t *testing.T) {
+	response := converter.OllamaResponse{
+		Models: []converter.OllamaModel{
+			{Name: "model1", Digest: "aaa"},
+		},
+	}
+
+	tempFile := filepath.Join(t.TempDir(), "models.json")
+	err := saveOllamaResponse(response, tempFile)
+	if err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+
+	loaded, err := loadOllamaResponse(tempFile)
+	if err != nil {
+		t.Fatalf("Expected no error loading, got %v", err)
+	}
+
+	if len(loaded.Models) != 1 || loaded.Models[0].Name != "model1" {
+		t.Errorf("Expected loaded model 'model1', got %v", loaded.Models)
+	}
+}
+
+func TestPrintDiffText(t *testing.T) {
+	diff := converter.DiffResponses(
+		converter.OllamaResponse{Models: []converter.OllamaModel{
+			{Name: "model1", Digest: "aaa", Details: converter.OllamaDetails{QuantizationLevel: "F16"}},
+			{Name: "model2", Digest: "bbb"},
+		}},
+		converter.OllamaResponse{Models: []converter.OllamaModel{
+			{Name: "model1", Digest: "aaa", Details: converter.OllamaDetails{QuantizationLevel: "Q8_0"}},
+			{Name: "model3", Digest: "ccc"},
+		}},
+	)
+
+	var buf bytes.Buffer
+	err := printDiff(&buf, diff, "text")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "+ model3 (ccc)\n- model2 (bbb)\n~ model1: quantization_level F16 -> Q8_0\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, buf.String())
+	}
+}
+
+func TestPrintDiffJSON(t *testing.T) {
+	diff := converter.DiffResponses(
+		converter.OllamaResponse{},
+		converter.OllamaResponse{Models: []converter.OllamaModel{{Name: "model1"}}},
+	)
+
+	var buf bytes.Buffer
+	err := printDiff(&buf, diff, "json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var parsed converter.ResponseDiff
+	err = json.Unmarshal(buf.Bytes(), &parsed)
+	if err != nil {
+		t.Fatalf("Expected valid JSON, got error %v", err)
+	}
+
+	if len(parsed.Added) != 1 || parsed.Added[0].Name != "model1" {
+		t.Errorf("Expected model1 to be added, got %v", parsed.Added)
+	}
+}
+
+func TestPrintDiffUnknownFormat(t *testing.T) {
+	err := printDiff(&bytes.Buffer{}, converter.ResponseDiff{}, "xml")
+	if err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("Expected unknown format error, got %v", err)
+	}
+}
+
+func TestDiffCmdExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before.json")
+	after := filepath.Join(dir, "after.json")
+	saveOllamaResponse(converter.OllamaResponse{Models: []converter.OllamaModel{{Name: "model1", Digest: "aaa"}}}, before)
+	saveOllamaResponse(converter.OllamaResponse{Models: []converter.OllamaModel{{Name: "model2", Digest: "bbb"}}}, after)
+
+	// Identical snapshots succeed
+	if err := diffCmd.RunE(diffCmd, []string{before, before}); err != nil {
+		t.Errorf("Expected no error for identical snapshots, got %v", err)
+	}
+
+	// Differences exit 1 without an error message
+	err := diffCmd.RunE(diffCmd, []string{before, after})
+	var codeErr exitCodeError
+	if !errors.As(err, &codeErr) || codeErr.code != 1 || codeErr.err != nil {
+		t.Errorf("Expected silent exit code 1, got %v", err)
+	}
+
+	// Unreadable files exit 2
+	err = diffCmd.RunE(diffCmd, []string{before, filepath.Join(dir, "missing.json")})
+	if exitCode(err) != 2 || !strings.Contains(err.Error(), "missing.json") {
+		t.Errorf("Expected exit code 2 naming the missing file, got %d for %v", exitCode(err), err)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"dmr-models-convert/pkg/converter"
+)
+
+// Exit codes for convert failures, so scripts can tell them apart
+const (
+	exitNetwork = 1 // DMR couldn't be reached or returned an error status; also other failures
+	exitParse   = 2 // DMR or input JSON couldn't be parsed
+	exitWrite   = 3 // the converted output couldn't be written
+	exitEmpty   = 4 // no models were found and --fail-on-empty is set
+)
+
+// errNoModels is returned by convert with --fail-on-empty when DMR has no models
+var errNoModels = errors.New("no models found")
+
+// exitCodeError sets the exit code for err explicitly. A nil err exits
+// silently, like diff's status 1 when the snapshots differ.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e exitCodeError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// outputError marks failures writing the converted output
+type outputError struct {
+	err error
+}
+
+func (e outputError) Error() string {
+	return e.err.Error()
+}
+
+func (e outputError) Unwrap() error {
+	return e.err
+}
+
+// exitCode maps a command error to its exit code
+func exitCode(err error) int {
+	var codeErr exitCodeError
+	var outErr outputError
+	switch {
+	case errors.As(err, &codeErr):
+		return codeErr.code
+	case errors.As(err, &outErr):
+		return exitWrite
+	case errors.Is(err, errNoModels):
+		return exitEmpty
+	case errors.Is(err, converter.ErrParse):
+		return exitParse
+	default:
+		return exitNetwork
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunConvertExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	validFile := filepath.Join(dir, "valid.json")
+	os.WriteFile(validFile, []byte(`[{"id": "sha256:aaa", "tags": ["model1"]}]`), 0644)
+	invalidFile := filepath.Join(dir, "invalid.json")
+	os.WriteFile(invalidFile, []byte(`{not json`), 0644)
+	emptyFile := filepath.Join(dir, "empty.json")
+	os.WriteFile(emptyFile, []byte(`[]`), 0644)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	savedURLs := dmrURLs
+	defer func() {
+		dmrURLs, dmrFiles, output, failOnEmpty = savedURLs, nil, "", false
+	}()
+
+	tests := []struct {
+		name        string
+		urls        []string
+		files       []string
+		output      string
+		failOnEmpty bool
+		expected    int
+	}{
+		{"network", []string{failing.URL}, nil, "", false, exitNetwork},
+		{"parse", nil, []string{invalidFile}, "", false, exitParse},
+		{"write", nil, []string{validFile}, filepath.Join(dir, "missing", "models.json"), false, exitWrite},
+		{"empty", nil, []string{emptyFile}, filepath.Join(dir, "models.json"), true, exitEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dmrURLs, dmrFiles, output, failOnEmpty = tt.urls, tt.files, tt.output, tt.failOnEmpty
+
+			err := runConvert(convertCmd, nil)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+
+			if code := exitCode(err); code != tt.expected {
+				t.Errorf("Expected exit code %d, got %d for error: %v", tt.expected, code, err)
+			}
+		})
+	}
+
+	// Empty output is only a failure with --fail-on-empty
+	dmrURLs, dmrFiles, output, failOnEmpty = nil, []string{emptyFile}, filepath.Join(dir, "models.json"), false
+	if err := runConvert(convertCmd, nil); err != nil {
+		t.Errorf("Expected no error without --fail-on-empty, got %v", err)
+	}
+}
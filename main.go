@@ -1,19 +1,111 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/template"
+	"time"
 
 	"dmr-models-convert/pkg/converter"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var (
 	// Used for flags
-	output string
-	dmrURL string
+	output                 string
+	dmrURLs                []string
+	dmrFiles               []string
+	concurrency            int
+	serverTimeout          time.Duration
+	connectTimeout         time.Duration
+	requestTimeout         time.Duration
+	useHTTP2               bool
+	followRedirects        bool
+	retryOnEmpty           bool
+	retries                int
+	retryDelay             time.Duration
+	fieldPaths             map[string]string
+	templates              map[string]string
+	stripPrefix            string
+	addPrefix              string
+	normalize              bool
+	nameTemplate           string
+	normalizeParameterSize bool
+	normalizeQuantization  bool
+	includeSizeString      bool
+	preserveUnknownFields  bool
+	normalizeDigestCase    bool
+	skipInvalid            bool
+	synthesizeLatest       bool
+	dumpDMR                string
+	replayDir              string
+	dmrAPIVersion          string
+	outputAPIFormat        string
+	dmrUnixSocket          string
+	dmrModelPath           string
+	validateResponse       bool
+	strictSize             string
+	strictDuplicates       bool
+	jsonErrors             bool
+	failOnEmpty            bool
+	splitOutput            bool
+	interval               time.Duration
+	history                bool
+	outputFormat           string
+	outputMode             string
+	detailsOnly            bool
+	outputFields           []string
+	stream                 bool
+	maxModels              int
+	since                  string
+	latestOnly             bool
+	dropEmptyFamilies      bool
+	keepAlive              time.Duration
+	defaultFamily          string
+	familyMapFile          string
+	keep                   int
+	webhook                string
+	webhookHeaders         map[string]string
+	webhookTimeout         time.Duration
+	webhookRetries         int
+	authTokenFile          string
+	headers                []string
+
+	// authToken is the DMR bearer token read from --auth-token-file
+	authToken string
+
+	// dmrHeaders are the DMR request headers parsed from --header
+	dmrHeaders http.Header
+
+	// sinceTime is the creation cutoff parsed from --since
+	sinceTime time.Time
+
+	// nameTmpl is the template parsed from --name-template
+	nameTmpl *template.Template
+
+	// outputPerm is the mode of saved files, parsed from --output-mode
+	outputPerm = defaultOutputPerm
+
+	// familyMap holds the architecture mappings read from --family-map-file
+	familyMap map[string]string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -23,10 +115,47 @@ var rootCmd = &cobra.Command{
 	Long: `A CLI tool that converts Docker Model Runner (DMR) API responses 
 to Ollama API format. This allows tools configured for Ollama to work 
 with DMR servers.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		// Execute the convert command by default
-		convertCmd.Run(cmd, args)
+	// Errors are reported by Execute, and usage only for invalid arguments
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if err := loadAuthToken(); err != nil {
+			return err
+		}
+		if err := loadReplayFiles(); err != nil {
+			return err
+		}
+		if err := loadFamilyMap(); err != nil {
+			return err
+		}
+		if err := converter.ValidateAPIVersion(dmrAPIVersion); err != nil {
+			return err
+		}
+		if strictSize != "" && strictSize != converter.StrictSizeFail && strictSize != converter.StrictSizeSkip {
+			return fmt.Errorf("invalid --strict-size %q (expected fail or skip)", strictSize)
+		}
+		if err := parseSince(time.Now()); err != nil {
+			return err
+		}
+		if err := parseNameTemplate(); err != nil {
+			return err
+		}
+		if err := parseOutputMode(); err != nil {
+			return err
+		}
+		if err := validateFormat(); err != nil {
+			return err
+		}
+		if err := validateHistory(); err != nil {
+			return err
+		}
+		if err := validateSplitOutput(); err != nil {
+			return err
+		}
+		return parseHeaders()
 	},
+	// Execute the convert command by default
+	RunE: runConvert,
 }
 
 // convertCmd represents the convert command
@@ -35,67 +164,783 @@ var convertCmd = &cobra.Command{
 	Short: "Convert DMR models to Ollama format",
 	Long: `Convert the models from DMR API format to Ollama API format 
 and save the result to the specified output file or print to stdout.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Fetching models from DMR server: %s\n", dmrURL)
+	// Reject stray arguments, e.g. "skip" in "--strict-size skip", which
+	// would otherwise be ignored while --strict-size runs in fail mode
+	Args: cobra.NoArgs,
+	RunE: runConvert,
+}
 
-		// Create converter instance
-		conv := converter.NewConverter()
+// runConvert runs the convert command once, or on every --interval tick
+// until interrupted, returning the error from a single run
+func runConvert(cmd *cobra.Command, args []string) error {
+	if interval <= 0 {
+		return convertAndSave()
+	}
+
+	// Re-run the conversion on every tick until interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-		// Fetch and convert models
-		ollamaResponse, err := conv.ConvertFromURL(dmrURL)
+	runEvery(ctx, interval, func(iteration int) {
+		fmt.Fprintf(os.Stderr, "Starting conversion run %d\n", iteration)
+		err := convertAndSave()
 		if err != nil {
-			fmt.Printf("Error converting DMR models: %v\n", err)
-			os.Exit(1)
+			writeError(os.Stderr, err, exitCode(err))
 		}
+	})
+	return nil
+}
 
-		fmt.Printf("Found %d models in DMR response\n", len(ollamaResponse.Models))
+// writeError writes err to w as "Error <message>", or with --json-errors as
+// a {"error": ..., "code": ...} JSON object for scripts
+func writeError(w io.Writer, err error, code int) {
+	if !jsonErrors {
+		fmt.Fprintf(w, "Error %v\n", err)
+		return
+	}
 
-		// Save converted JSON to output file or print to stdout
-		if output != "" {
-			err = saveOllamaResponse(ollamaResponse, output)
-			if err != nil {
-				fmt.Printf("Error saving output file: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("Successfully converted and saved to: %s\n", output)
-		} else {
-			err = printOllamaResponse(ollamaResponse)
-			if err != nil {
-				fmt.Printf("Error printing JSON: %v\n", err)
-				os.Exit(1)
-			}
-		}
-	},
+	jsonData, marshalErr := json.Marshal(struct {
+		Error string `json:"error"`
+		Code  int    `json:"code"`
+	}{err.Error(), code})
+	if marshalErr != nil {
+		fmt.Fprintf(w, "Error %v\n", err)
+		return
+	}
+	fmt.Fprintln(w, string(jsonData))
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Command errors are reported here and mapped to the process exit code.
 func Execute() {
 	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	if err == nil {
+		return
+	}
+
+	var codeErr exitCodeError
+	if errors.As(err, &codeErr) && codeErr.err == nil {
+		os.Exit(codeErr.code)
 	}
+	writeError(os.Stderr, err, exitCode(err))
+	os.Exit(exitCode(err))
 }
 
 func init() {
 	// Root command flags (available for all commands)
-	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "", "Output file path for converted JSON (optional, prints to stdout if not specified)")
-	rootCmd.PersistentFlags().StringVarP(&dmrURL, "dmr", "d", "http://localhost:12434/models", "DMR server URL (optional, defaults to http://localhost:12434/models)")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "", "Output file path for converted JSON, or - for stdout (optional, prints to stdout if not specified)")
+	rootCmd.PersistentFlags().StringSliceVarP(&dmrURLs, "dmr", "d", []string{"http://localhost:12434/models"}, "DMR server URL, repeat or comma-separate to aggregate several servers; ${VAR} references are expanded from the environment (optional, defaults to http://localhost:12434/models)")
+	rootCmd.PersistentFlags().StringSliceVarP(&dmrFiles, "file", "f", nil, "Read DMR JSON from files instead of fetching; accepts repeated paths or globs, merged by digest")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", converter.DefaultConcurrency, "Maximum number of DMR servers to fetch from at once")
+	rootCmd.PersistentFlags().StringVar(&authTokenFile, "auth-token-file", "", "Read a bearer token for DMR requests from this file, keeping it out of the process list")
+	rootCmd.PersistentFlags().StringArrayVar(&headers, "header", nil, "Header to send with DMR requests, e.g. \"X-Feature: beta\" (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&dmrUnixSocket, "dmr-unix-socket", "", "Connect to DMR through this Unix socket, e.g. /var/run/docker.sock with --dmr http://localhost/exp/vDD4.40/models")
+	rootCmd.PersistentFlags().StringVar(&dmrModelPath, "dmr-model-path", converter.DefaultModelPath, "Path of DMR's single-model endpoint used by show, appended to the --dmr URL with {name} replaced by the model name")
+	rootCmd.PersistentFlags().DurationVar(&connectTimeout, "connect-timeout", 30*time.Second, "How long to wait for a connection to a DMR server")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", 30*time.Second, "How long a whole DMR request, including reading the response, may take")
+	rootCmd.PersistentFlags().BoolVar(&useHTTP2, "http2", false, "Use HTTP/2 (h2c) for http:// DMR URLs, falling back to HTTP/1.1; https:// always negotiates HTTP/2")
+	rootCmd.PersistentFlags().BoolVar(&followRedirects, "follow-redirects", true, "Follow HTTP redirects from DMR; with --follow-redirects=false a redirect fails with its Location")
+	rootCmd.PersistentFlags().DurationVar(&serverTimeout, "timeout-per-server", 0, "Skip DMR servers that take longer than this to respond (e.g. 5s, default 0 waits for all)")
+	rootCmd.PersistentFlags().BoolVar(&retryOnEmpty, "retry-on-empty", false, "Re-fetch when DMR returns no models, e.g. while it is still starting up")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 3, "With --retry-on-empty, how many times to re-fetch")
+	rootCmd.PersistentFlags().DurationVar(&retryDelay, "retry-delay", 2*time.Second, "With --retry-on-empty or --webhook, how long to wait between attempts")
+	rootCmd.PersistentFlags().StringToStringVar(&fieldPaths, "field-path", nil, "Read a DMR config field from another location, e.g. parameters=$.descriptor.params (repeatable)")
+	rootCmd.PersistentFlags().StringToStringVar(&templates, "template", nil, "Prompt TEMPLATE for a family in show output, e.g. llama='{{ .Prompt }}' (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&stripPrefix, "strip-prefix", "", "Prefix to remove from converted model names (e.g. \"ai/\")")
+	rootCmd.PersistentFlags().StringVar(&addPrefix, "add-prefix", "", "Prefix to add to converted model names, applied after --strip-prefix")
+	rootCmd.PersistentFlags().DurationVar(&interval, "interval", 0, "Re-run the conversion on this interval until interrupted (e.g. 5m, default 0 runs once)")
+	rootCmd.PersistentFlags().BoolVar(&splitOutput, "split-output", false, "Treat --output as a directory and write one JSON file per model (implied when --output ends in \"/\")")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", "json", "Output format: json, names for one model name per line, or csv")
+	rootCmd.PersistentFlags().StringVar(&outputMode, "output-mode", "", "Octal file mode for saved files, e.g. 0600, also applied to existing files (default 0644 less the umask)")
+	rootCmd.PersistentFlags().BoolVar(&detailsOnly, "details-only", false, "Output a compact JSON array of each model's name, family, parameter_size and quantization_level")
+	rootCmd.PersistentFlags().StringVar(&outputAPIFormat, "output-format", "ollama", "API shape of JSON output: ollama for /api/tags, or openai for /v1/models")
+	rootCmd.PersistentFlags().StringSliceVar(&outputFields, "fields", nil, "Comma-separated model fields to include in JSON output (e.g. name,digest,family)")
+	rootCmd.PersistentFlags().BoolVar(&latestOnly, "latest-only", false, "Keep only the :latest tag per model, or its first tag when there is none")
+	rootCmd.PersistentFlags().BoolVar(&dropEmptyFamilies, "drop-empty-families", false, "Leave out models whose family can't be resolved from their architecture or tags")
+	rootCmd.PersistentFlags().StringVar(&defaultFamily, "default-family", "", "Family to report for unrecognized architectures instead of the raw architecture (e.g. llama)")
+	rootCmd.PersistentFlags().StringVar(&familyMapFile, "family-map-file", "", "JSON or YAML file of architecture: family mappings that override the built-in families")
+	rootCmd.PersistentFlags().DurationVar(&keepAlive, "keep-alive", 0, "Set each model's expires_at to now plus this duration (e.g. 5m, default 0 omits it)")
+	rootCmd.PersistentFlags().IntVar(&maxModels, "max-models", 0, "Keep at most this many converted models (default 0 for no limit)")
+	rootCmd.PersistentFlags().StringVar(&since, "since", "", "Only include models created within this duration (e.g. 24h) or at or after this RFC3339 time")
+	rootCmd.PersistentFlags().BoolVar(&stream, "stream", false, "Write JSON output one model at a time instead of building it in memory")
+	rootCmd.PersistentFlags().BoolVar(&history, "history", false, "Treat --output as a directory and write a new timestamped ollama-models-<RFC3339>.json file each run")
+	rootCmd.PersistentFlags().IntVar(&keep, "keep", 0, "With --history, keep only the newest N files (default 0 keeps all)")
+	rootCmd.PersistentFlags().StringVar(&webhook, "webhook", "", "POST the converted models, in the --format, to this URL instead of writing a file")
+	rootCmd.PersistentFlags().StringToStringVar(&webhookHeaders, "webhook-header", nil, "Header to send with --webhook, e.g. Authorization='Bearer token' (repeatable)")
+	rootCmd.PersistentFlags().DurationVar(&webhookTimeout, "webhook-timeout", 10*time.Second, "Timeout for each --webhook request")
+	rootCmd.PersistentFlags().IntVar(&webhookRetries, "webhook-retries", 3, "How many times to retry a failed --webhook request")
+	rootCmd.PersistentFlags().BoolVar(&normalizeParameterSize, "normalize-parameter-size", false, "Rewrite parameter sizes in Ollama's form, e.g. \"1500 M\" becomes \"1.5B\"")
+	rootCmd.PersistentFlags().BoolVar(&normalizeQuantization, "normalize-quantization", false, "Rewrite quantization levels with Ollama's casing, e.g. q4_k_m becomes Q4_K_M")
+	rootCmd.PersistentFlags().BoolVar(&normalizeDigestCase, "normalize-digest-case", true, "Lowercase model digests, as Ollama clients compare them case-sensitively")
+	rootCmd.PersistentFlags().StringVar(&dmrAPIVersion, "dmr-api-version", converter.DMRAPIAuto, "DMR response shape to accept: v0 for a bare model array, v1 for a {\"models\": [...]} object, or auto to detect it")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Read DMR JSON from every .json file in this directory instead of fetching, e.g. files saved with --dump-dmr")
+	rootCmd.PersistentFlags().StringVar(&dumpDMR, "dump-dmr", "", "Also save the raw DMR responses, pretty-printed, to this file for --file fixtures; several servers or pages are numbered like dmr-1.json")
+	rootCmd.PersistentFlags().BoolVar(&synthesizeLatest, "synthesize-latest", false, "Add a <name>:latest alias of the first variant of each model without a :latest tag")
+	rootCmd.PersistentFlags().BoolVar(&skipInvalid, "skip-invalid", false, "Leave out DMR models that have neither tags nor an ID, logging each one")
+	rootCmd.PersistentFlags().BoolVar(&includeSizeString, "include-size-string", false, "Add each model's raw DMR size as size_string, for debugging size parsing")
+	rootCmd.PersistentFlags().BoolVar(&preserveUnknownFields, "preserve-unknown-fields", false, "Pass DMR config fields this tool doesn't know through as each model's extra")
+	rootCmd.PersistentFlags().BoolVar(&jsonErrors, "json-errors", false, "Print errors to stderr as JSON objects like {\"error\": \"...\", \"code\": 1}")
+	rootCmd.PersistentFlags().StringVar(&strictSize, "strict-size", "", "Fail on DMR sizes that can't be parsed instead of reporting 0, or with --strict-size=skip (not \"--strict-size skip\") leave those models out")
+	rootCmd.PersistentFlags().Lookup("strict-size").NoOptDefVal = converter.StrictSizeFail
+	rootCmd.PersistentFlags().BoolVar(&strictDuplicates, "strict-duplicates", false, "Fail when DMR models with different digests convert to the same name, instead of only warning")
+	rootCmd.PersistentFlags().BoolVar(&validateResponse, "validate", false, "Check the converted models have the fields Ollama clients need before writing, failing otherwise")
+	rootCmd.PersistentFlags().BoolVar(&failOnEmpty, "fail-on-empty", false, "Exit with status 4 instead of writing output when no models are found")
+	rootCmd.PersistentFlags().BoolVar(&normalize, "normalize-names", false, "Lowercase converted model names, keeping the original in tags")
+	rootCmd.PersistentFlags().StringVar(&nameTemplate, "name-template", converter.DefaultNameTemplate, "Go template for model names, with {{.Name}}, {{.Family}}, {{.ParameterSize}}, {{.QuantizationLevel}}, {{.Format}} and {{.Digest}}")
 
 	// Add the convert command to root
 	rootCmd.AddCommand(convertCmd)
 }
 
+// expandDMRURLs expands ${VAR} references in each DMR URL from the
+// environment, so templated configs like "http://dmr:${DMR_PORT}/models"
+// work. References to unset variables are left as written.
+func expandDMRURLs(urls []string) []string {
+	expanded := make([]string, len(urls))
+	for i, dmrURL := range urls {
+		expanded[i] = os.Expand(dmrURL, func(name string) string {
+			if value, ok := os.LookupEnv(name); ok {
+				return value
+			}
+			return "${" + name + "}"
+		})
+	}
+	return expanded
+}
+
+// progressReporter returns a Progress callback that writes a
+// "Fetched X/N DMR servers" line to w as each server completes, when
+// there is more than one server
+func progressReporter(w io.Writer) func(done, total int) {
+	return func(done, total int) {
+		if total > 1 {
+			fmt.Fprintf(w, "Fetched %d/%d DMR servers\n", done, total)
+		}
+	}
+}
+
+// isTerminal reports whether f is a character device such as a terminal,
+// so progress isn't mixed into redirected output or logs
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// loadAuthToken reads the DMR bearer token from --auth-token-file, if set,
+// trimming surrounding whitespace such as a trailing newline
+func loadAuthToken() error {
+	authToken = ""
+	if authTokenFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(authTokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read auth token file: %w", err)
+	}
+
+	authToken = strings.TrimSpace(string(data))
+	if authToken == "" {
+		return fmt.Errorf("auth token file %s is empty", authTokenFile)
+	}
+	return nil
+}
+
+// loadReplayFiles adds the .json files in --replay to the DMR files, so
+// recorded responses are converted as if fetched
+func loadReplayFiles() error {
+	if replayDir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(replayDir)
+	if err != nil {
+		return fmt.Errorf("failed to read replay directory: %w", err)
+	}
+
+	found := false
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			dmrFiles = append(dmrFiles, filepath.Join(replayDir, entry.Name()))
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no .json files in replay directory %s", replayDir)
+	}
+	return nil
+}
+
+// loadFamilyMap reads the architecture to family mappings in
+// --family-map-file, as JSON for a .json file and YAML otherwise
+func loadFamilyMap() error {
+	familyMap = nil
+	if familyMapFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(familyMapFile)
+	if err != nil {
+		return fmt.Errorf("failed to read family map file: %w", err)
+	}
+
+	var entries map[string]string
+	if strings.EqualFold(filepath.Ext(familyMapFile), ".json") {
+		err = json.Unmarshal(data, &entries)
+	} else {
+		err = yaml.Unmarshal(data, &entries)
+	}
+	if err != nil {
+		return fmt.Errorf("malformed family map file %s: %w", familyMapFile, err)
+	}
+
+	familyMap = make(map[string]string, len(entries))
+	for architecture, family := range entries {
+		if strings.TrimSpace(architecture) == "" || strings.TrimSpace(family) == "" {
+			return fmt.Errorf("malformed family map file %s: architecture %q maps to %q", familyMapFile, architecture, family)
+		}
+		familyMap[strings.ToLower(architecture)] = family
+	}
+	return nil
+}
+
+// parseHeaders parses each --header "Key: Value" into dmrHeaders,
+// rejecting entries without a colon or with an invalid name or value
+func parseHeaders() error {
+	dmrHeaders = nil
+	for _, header := range headers {
+		key, value, found := strings.Cut(header, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found || !validHeaderName(key) || strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid header %q: expected \"Key: Value\"", header)
+		}
+
+		if dmrHeaders == nil {
+			dmrHeaders = make(http.Header)
+		}
+		dmrHeaders.Add(key, value)
+	}
+	return nil
+}
+
+// parseSince parses --since into sinceTime, counting a duration back from now
+func parseSince(now time.Time) error {
+	sinceTime = time.Time{}
+	if since == "" {
+		return nil
+	}
+
+	var err error
+	sinceTime, err = converter.ParseSince(since, now)
+	return err
+}
+
+// parseNameTemplate parses --name-template into nameTmpl, leaving it nil for
+// the default template, which keeps the converted names
+func parseNameTemplate() error {
+	nameTmpl = nil
+	if nameTemplate == converter.DefaultNameTemplate {
+		return nil
+	}
+
+	tmpl, err := converter.ParseNameTemplate(nameTemplate)
+	if err != nil {
+		return fmt.Errorf("invalid --name-template: %w", err)
+	}
+	nameTmpl = tmpl
+	return nil
+}
+
+// defaultOutputPerm is the mode of saved files without --output-mode
+const defaultOutputPerm os.FileMode = 0644
+
+// parseOutputMode parses the octal --output-mode into outputPerm
+func parseOutputMode() error {
+	outputPerm = defaultOutputPerm
+	if outputMode == "" {
+		return nil
+	}
+
+	mode, err := strconv.ParseUint(outputMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return fmt.Errorf("invalid --output-mode %q: expected octal permissions like 0600", outputMode)
+	}
+	outputPerm = os.FileMode(mode)
+	return nil
+}
+
+// writeOutputFile writes data to filename with outputPerm. With
+// --output-mode the mode is set exactly, even on an existing file, which
+// os.WriteFile would otherwise leave alone.
+func writeOutputFile(filename string, data []byte) error {
+	err := os.WriteFile(filename, data, outputPerm)
+	if err != nil || outputMode == "" {
+		return err
+	}
+	return os.Chmod(filename, outputPerm)
+}
+
+// createOutputFile creates or truncates filename for writing with
+// outputPerm, like writeOutputFile
+func createOutputFile(filename string) (*os.File, error) {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, outputPerm)
+	if err != nil || outputMode == "" {
+		return file, err
+	}
+
+	if err := file.Chmod(outputPerm); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// validHeaderName reports whether name is a non-empty RFC 9110 token
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// newConverter creates a converter configured from the command line flags
+func newConverter() *converter.Converter {
+	conv := converter.NewConverterWithClient(converter.NewHTTPClient(converter.HTTPClientOptions{
+		ConnectTimeout: connectTimeout,
+		Timeout:        requestTimeout,
+		HTTP2:          useHTTP2,
+		UnixSocket:     dmrUnixSocket,
+
+		DisableRedirects: !followRedirects,
+	}))
+	conv.Concurrency = concurrency
+	conv.ServerTimeout = serverTimeout
+	conv.FieldPaths = fieldPaths
+	conv.Templates = templates
+	conv.MaxModels = maxModels
+	conv.ModelPath = dmrModelPath
+	conv.Since = sinceTime
+	conv.KeepAlive = keepAlive
+	conv.DefaultFamily = defaultFamily
+	conv.FamilyMap = familyMap
+	conv.NormalizeParameterSizes = normalizeParameterSize
+	conv.NormalizeQuantizations = normalizeQuantization
+	conv.IncludeSizeString = includeSizeString
+	conv.PreserveUnknownFields = preserveUnknownFields
+	conv.PreserveDigestCase = !normalizeDigestCase
+	conv.SkipInvalid = skipInvalid
+	conv.SynthesizeLatest = synthesizeLatest
+	conv.APIVersion = dmrAPIVersion
+	conv.StrictSize = strictSize
+	conv.StrictDuplicates = strictDuplicates
+	conv.AuthToken = authToken
+	conv.Headers = dmrHeaders
+	conv.Logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if isTerminal(os.Stderr) {
+		conv.Progress = progressReporter(os.Stderr)
+	}
+
+	var transforms []func(converter.OllamaModel) converter.OllamaModel
+	if dropEmptyFamilies {
+		transforms = append(transforms, converter.DropEmptyFamily)
+	}
+	if latestOnly {
+		transforms = append(transforms, converter.LatestOnly)
+	}
+	if stripPrefix != "" || addPrefix != "" {
+		transforms = append(transforms, converter.RewritePrefix(stripPrefix, addPrefix))
+	}
+	if normalize {
+		transforms = append(transforms, converter.NormalizeNames)
+	}
+	if len(transforms) > 0 {
+		conv.ModelTransform = converter.ChainTransforms(transforms...)
+	}
+	conv.NameTemplate = nameTmpl
+
+	return conv
+}
+
+// convertAndSave fetches and converts the DMR models, then saves or prints the result
+func convertAndSave() error {
+	err := validateFields(outputFields)
+	if err != nil {
+		return fmt.Errorf("checking --fields: %w", err)
+	}
+
+	// Measure a --since duration from this run, not from startup, so each
+	// --interval run keeps the same window
+	err = parseSince(time.Now())
+	if err != nil {
+		return fmt.Errorf("checking --since: %w", err)
+	}
+
+	err = validateOutputFormat()
+	if err != nil {
+		return fmt.Errorf("checking --output-format: %w", err)
+	}
+
+	err = validateDetailsOnly()
+	if err != nil {
+		return fmt.Errorf("checking --details-only: %w", err)
+	}
+
+	if stream {
+		err = validateStream()
+		if err != nil {
+			return fmt.Errorf("checking --stream: %w", err)
+		}
+		return streamConvertAndSave()
+	}
+
+	var ollamaResponse converter.OllamaResponse
+	if len(dmrFiles) > 0 {
+		// Convert saved DMR responses instead of fetching
+		var filesRead int
+		ollamaResponse, filesRead, err = convertDMRFiles(newConverter(), dmrFiles)
+		if err != nil {
+			return fmt.Errorf("converting DMR files: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Read %d DMR files\n", filesRead)
+	} else {
+		for _, dmrURL := range expandDMRURLs(dmrURLs) {
+			fmt.Fprintf(os.Stderr, "Fetching models from DMR server: %s\n", dmrURL)
+		}
+
+		// Fetch and convert models from all servers
+		ollamaResponse, err = fetchModels()
+		if err != nil {
+			return fmt.Errorf("converting DMR models: %w", err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Found %d models in DMR response\n", len(ollamaResponse.Models))
+	if failOnEmpty && len(ollamaResponse.Models) == 0 {
+		return errNoModels
+	}
+
+	if validateResponse {
+		err = converter.ValidateOllamaResponse(ollamaResponse)
+		if err != nil {
+			return fmt.Errorf("validating converted models: %w", err)
+		}
+	}
+
+	// Save converted JSON to output file or print to stdout
+	return selectOutputWriter().Write(ollamaResponse)
+}
+
+// outputWriter is a destination for the convert command's response, such as
+// a file, stdout, or a webhook
+type outputWriter interface {
+	Write(response converter.OllamaResponse) error
+}
+
+// selectOutputWriter picks where the convert command writes its response
+// based on --output, --history, --split-output and --format
+func selectOutputWriter() outputWriter {
+	switch {
+	case webhook != "":
+		return newWebhookOutput()
+	case writesToStdout():
+		return streamOutput{w: os.Stdout, format: outputFormat}
+	case history:
+		return historyOutput{dir: output, keep: keep}
+	case splitOutput || strings.HasSuffix(output, "/"):
+		return splitDirOutput{dir: output}
+	default:
+		return fileOutput{filename: output, format: outputFormat}
+	}
+}
+
+// stdoutOutput is the --output value that explicitly asks for stdout
+const stdoutOutput = "-"
+
+// writesToStdout reports whether --output is empty or "-", so the result
+// goes to stdout instead of a file
+func writesToStdout() bool {
+	return output == "" || output == stdoutOutput
+}
+
+// historyOutput saves each response to a new timestamped file in dir
+type historyOutput struct {
+	dir  string
+	keep int
+}
+
+func (h historyOutput) Write(response converter.OllamaResponse) error {
+	filename, err := saveHistoryOllamaResponse(response, h.dir, time.Now(), h.keep)
+	if err != nil {
+		return outputError{fmt.Errorf("saving output file: %w", err)}
+	}
+	fmt.Fprintf(os.Stderr, "Successfully converted and saved to: %s\n", filename)
+	return nil
+}
+
+// splitDirOutput saves each model to its own file in dir
+type splitDirOutput struct {
+	dir string
+}
+
+func (s splitDirOutput) Write(response converter.OllamaResponse) error {
+	err := saveSplitOllamaResponse(response, s.dir)
+	if err != nil {
+		return outputError{fmt.Errorf("saving output files: %w", err)}
+	}
+	fmt.Fprintf(os.Stderr, "Successfully converted and saved %d model files to: %s\n", len(response.Models), s.dir)
+	return nil
+}
+
+// fileOutput saves the response to a single file in the output format
+type fileOutput struct {
+	filename string
+	format   string
+}
+
+func (f fileOutput) Write(response converter.OllamaResponse) error {
+	err := saveFormattedResponse(response, f.filename, f.format)
+	if err != nil {
+		return outputError{fmt.Errorf("saving output file: %w", err)}
+	}
+	fmt.Fprintf(os.Stderr, "Successfully converted and saved to: %s\n", f.filename)
+	return nil
+}
+
+// streamOutput writes the response to w in the output format
+type streamOutput struct {
+	w      io.Writer
+	format string
+}
+
+func (s streamOutput) Write(response converter.OllamaResponse) error {
+	err := writeFormattedResponse(s.w, response, s.format)
+	if err != nil {
+		return outputError{fmt.Errorf("printing output: %w", err)}
+	}
+	return nil
+}
+
+// streamConvertAndSave converts the DMR models and streams the JSON to the
+// output file or stdout one model at a time
+func streamConvertAndSave() error {
+	conv := newConverter()
+	defer conv.Close()
+	recorder := recordDMRResponses(conv)
+
+	dmrModels, err := loadDMRModels(conv)
+	if err != nil {
+		return fmt.Errorf("converting DMR models: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Found %d models in DMR response\n", len(dmrModels))
+
+	if recorder != nil {
+		err = recorder.dump(dumpDMR)
+		if err != nil {
+			return err
+		}
+	}
+
+	if failOnEmpty && len(dmrModels) == 0 {
+		return errNoModels
+	}
+
+	if writesToStdout() {
+		written, err := conv.StreamConvertCount(os.Stdout, dmrModels)
+		if err == nil {
+			_, err = fmt.Println()
+		}
+		if err != nil {
+			return outputError{fmt.Errorf("printing output: %w", err)}
+		}
+		if failOnEmpty && written == 0 {
+			return errNoModels
+		}
+		return nil
+	}
+
+	// Stream to a temporary file and only replace the output once the whole
+	// response is written and not rejected as empty
+	tmpName := output + ".tmp"
+	file, err := createOutputFile(tmpName)
+	if err != nil {
+		return outputError{fmt.Errorf("saving output file: %w", err)}
+	}
+	defer os.Remove(tmpName)
+
+	written, err := conv.StreamConvertCount(file, dmrModels)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return outputError{fmt.Errorf("saving output file: %w", err)}
+	}
+	if failOnEmpty && written == 0 {
+		return errNoModels
+	}
+
+	err = os.Rename(tmpName, output)
+	if err != nil {
+		return outputError{fmt.Errorf("saving output file: %w", err)}
+	}
+	fmt.Fprintf(os.Stderr, "Successfully converted and saved to: %s\n", output)
+
+	return nil
+}
+
+// runEvery calls fn immediately and then once per interval until ctx is done
+func runEvery(ctx context.Context, interval time.Duration, fn func(iteration int)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for iteration := 1; ; iteration++ {
+		fn(iteration)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchModels fetches and converts the models from all configured DMR
+// servers, or from the DMR files when --file is set
+func fetchModels() (converter.OllamaResponse, error) {
+	conv := newConverter()
+	defer conv.Close()
+
+	if len(dmrFiles) > 0 {
+		response, _, err := convertDMRFiles(conv, dmrFiles)
+		return response, err
+	}
+
+	// DMR can briefly return an empty list while models register at startup
+	attempts := 1
+	if retryOnEmpty {
+		attempts += retries
+	}
+	for attempt := 1; ; attempt++ {
+		response, err := convertFromURLs(conv, expandDMRURLs(dmrURLs))
+		if err != nil || len(response.Models) > 0 || attempt >= attempts {
+			return response, err
+		}
+		fmt.Fprintf(os.Stderr, "DMR returned no models, retrying in %s (attempt %d of %d)\n", retryDelay, attempt+1, attempts)
+		time.Sleep(retryDelay)
+	}
+}
+
+// convertFromURLs fetches and converts the models like ConvertFromURLs,
+// saving the raw DMR responses to --dump-dmr first when it is set
+func convertFromURLs(conv *converter.Converter, urls []string) (converter.OllamaResponse, error) {
+	ctx := context.Background()
+	recorder := recordDMRResponses(conv)
+	dmrModels, err := conv.FetchDMRModelsFromURLs(ctx, urls)
+	if err != nil && ctx.Err() != nil {
+		return converter.OllamaResponse{}, err
+	}
+
+	if recorder != nil {
+		if dumpErr := recorder.dump(dumpDMR); dumpErr != nil {
+			return converter.OllamaResponse{}, dumpErr
+		}
+	}
+
+	response, convertErr := conv.ConvertDMRToOllamaContext(ctx, dmrModels)
+	if convertErr != nil {
+		return converter.OllamaResponse{}, convertErr
+	}
+	return response, err
+}
+
+// dmrRecorder collects the raw DMR response bodies for --dump-dmr
+type dmrRecorder struct {
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+// recordDMRResponses records conv's DMR responses when --dump-dmr is set
+// and DMR is fetched rather than read from --file, or returns nil
+func recordDMRResponses(conv *converter.Converter) *dmrRecorder {
+	if dumpDMR == "" || len(dmrFiles) > 0 {
+		return nil
+	}
+
+	recorder := &dmrRecorder{}
+	conv.RecordResponse = func(url string, body []byte) {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		recorder.bodies = append(recorder.bodies, body)
+	}
+	return recorder
+}
+
+// dump saves the recorded responses exactly as DMR returned them, only
+// pretty-printed, so --file can read them back. Several responses, from
+// several servers or pages, go to numbered files like dmr-1.json and
+// dmr-2.json instead.
+func (r *dmrRecorder) dump(filename string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, body := range r.bodies {
+		name := filename
+		if len(r.bodies) > 1 {
+			ext := filepath.Ext(filename)
+			name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(filename, ext), i+1, ext)
+		}
+
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", "  "); err != nil {
+			// Keep a body that isn't valid JSON as it is, for debugging
+			indented.Reset()
+			indented.Write(body)
+		}
+
+		if err := writeOutputFile(name, indented.Bytes()); err != nil {
+			return outputError{fmt.Errorf("saving DMR dump: %w", err)}
+		}
+		fmt.Fprintf(os.Stderr, "Saved DMR response to: %s\n", name)
+	}
+	return nil
+}
+
+// convertDMRFiles converts saved DMR JSON files matching the given paths or
+// globs. The models of all files are merged by ID and converted together,
+// so --max-models and duplicate name checks see the combined list. It
+// returns the number of files read.
+func convertDMRFiles(conv *converter.Converter, patterns []string) (converter.OllamaResponse, int, error) {
+	dmrModels, filesRead, err := readDMRFiles(conv, patterns)
+	if err != nil {
+		return converter.OllamaResponse{}, 0, err
+	}
+
+	response, err := conv.ConvertDMRToOllamaContext(context.Background(), dmrModels)
+	if err != nil {
+		return converter.OllamaResponse{}, 0, err
+	}
+	return response, filesRead, nil
+}
+
 // saveOllamaResponse saves the Ollama response to a JSON file
 func saveOllamaResponse(response converter.OllamaResponse, filename string) error {
-	// Create pretty-printed JSON
-	jsonData, err := json.MarshalIndent(response, "", "  ")
+	return saveFormattedResponse(response, filename, "json")
+}
+
+// saveFormattedResponse saves the Ollama response to a file in the given output format
+func saveFormattedResponse(response converter.OllamaResponse, filename, format string) error {
+	data, err := encodeOllamaResponse(response, format)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return err
 	}
 
 	// Write to file
-	err = os.WriteFile(filename, jsonData, 0644)
+	err = writeOutputFile(filename, data)
 	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
@@ -103,17 +948,323 @@ func saveOllamaResponse(response converter.OllamaResponse, filename string) erro
 	return nil
 }
 
+// jsonOutputValue returns what the json format encodes for the response,
+// applying --details-only, --output-format and --fields
+func jsonOutputValue(response converter.OllamaResponse) (any, error) {
+	if detailsOnly {
+		return converter.SummarizeDetails(response), nil
+	}
+	if len(outputFields) > 0 {
+		return trimFields(response, outputFields)
+	}
+	if outputAPIFormat == "openai" {
+		return converter.ConvertOllamaToOpenAI(response), nil
+	}
+	return response, nil
+}
+
+// encodeOllamaResponse renders the Ollama response in the given output format
+func encodeOllamaResponse(response converter.OllamaResponse, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		value, err := jsonOutputValue(response)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create pretty-printed JSON
+		jsonData, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return jsonData, nil
+	case "names":
+		var buf bytes.Buffer
+		for _, model := range response.Models {
+			buf.WriteString(model.Name + "\n")
+		}
+		return buf.Bytes(), nil
+	case "csv":
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.Write([]string{"name", "family", "parameter_size", "quantization_level", "size_bytes", "modified_at", "digest"})
+		for _, model := range response.Models {
+			writer.Write([]string{
+				model.Name,
+				model.Details.Family,
+				model.Details.ParameterSize,
+				model.Details.QuantizationLevel,
+				strconv.FormatInt(model.Size, 10),
+				model.ModifiedAt,
+				model.Digest,
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return nil, fmt.Errorf("failed to write CSV: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// unsafeFilenameChars matches characters that are replaced when building file names
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizeFilename turns a model name into a filesystem-safe file name
+func sanitizeFilename(name string) string {
+	name = unsafeFilenameChars.ReplaceAllString(name, "_")
+	name = strings.Trim(name, "._")
+	if name == "" {
+		name = "model"
+	}
+	return name
+}
+
+// saveSplitOllamaResponse saves each model to its own JSON file in dir
+func saveSplitOllamaResponse(response converter.OllamaResponse, dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	used := make(map[string]bool)
+	for _, model := range response.Models {
+		name := sanitizeFilename(model.Name)
+		// Different names can sanitize to the same file name, so disambiguate with the digest
+		if used[name] {
+			name += "-" + sanitizeFilename(model.Digest)
+		}
+		used[name] = true
+
+		jsonData, err := json.MarshalIndent(model, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+
+		err = writeOutputFile(filepath.Join(dir, name+".json"), jsonData)
+		if err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// historyFilePrefix and historyFileSuffix surround the timestamp in history file names
+const (
+	historyFilePrefix = "ollama-models-"
+	historyFileSuffix = ".json"
+)
+
+// saveHistoryOllamaResponse saves the response to a timestamped file in dir
+// and prunes older history files so at most keep remain. A keep of 0 or
+// less keeps every file. It returns the path of the new file.
+func saveHistoryOllamaResponse(response converter.OllamaResponse, dir string, now time.Time, keep int) (string, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// UTC timestamps sort lexically in time order, which pruning relies on
+	filename := filepath.Join(dir, historyFilePrefix+now.UTC().Format(time.RFC3339)+historyFileSuffix)
+	err = saveOllamaResponse(response, filename)
+	if err != nil {
+		return "", err
+	}
+
+	if keep > 0 {
+		matches, err := filepath.Glob(filepath.Join(dir, historyFilePrefix+"*"+historyFileSuffix))
+		if err != nil {
+			return "", fmt.Errorf("failed to list history files: %w", err)
+		}
+		sort.Strings(matches)
+		for len(matches) > keep {
+			err = os.Remove(matches[0])
+			if err != nil {
+				return "", fmt.Errorf("failed to prune history file: %w", err)
+			}
+			matches = matches[1:]
+		}
+	}
+
+	return filename, nil
+}
+
+// modelFields maps the names accepted by --fields to their values in a model.
+// Detail fields are lifted to the top level of the trimmed model.
+var modelFields = map[string]func(converter.OllamaModel) any{
+	"name":               func(m converter.OllamaModel) any { return m.Name },
+	"model":              func(m converter.OllamaModel) any { return m.Model },
+	"modified_at":        func(m converter.OllamaModel) any { return m.ModifiedAt },
+	"expires_at":         func(m converter.OllamaModel) any { return m.ExpiresAt },
+	"size":               func(m converter.OllamaModel) any { return m.Size },
+	"size_string":        func(m converter.OllamaModel) any { return m.SizeString },
+	"extra":              func(m converter.OllamaModel) any { return m.Extra },
+	"digest":             func(m converter.OllamaModel) any { return m.Digest },
+	"details":            func(m converter.OllamaModel) any { return m.Details },
+	"tags":               func(m converter.OllamaModel) any { return m.Tags },
+	"parent_model":       func(m converter.OllamaModel) any { return m.Details.ParentModel },
+	"format":             func(m converter.OllamaModel) any { return m.Details.Format },
+	"family":             func(m converter.OllamaModel) any { return m.Details.Family },
+	"families":           func(m converter.OllamaModel) any { return m.Details.Families },
+	"parameter_size":     func(m converter.OllamaModel) any { return m.Details.ParameterSize },
+	"quantization_level": func(m converter.OllamaModel) any { return m.Details.QuantizationLevel },
+}
+
+// validateOutputFormat checks --output-format, and that the OpenAI shape is
+// only combined with output options that write a single JSON model list
+func validateOutputFormat() error {
+	switch outputAPIFormat {
+	case "ollama":
+		return nil
+	case "openai":
+		if outputFormat != "json" || len(outputFields) > 0 || stream || splitOutput || strings.HasSuffix(output, "/") {
+			return errors.New("openai output requires --format json and can't be combined with --fields, --stream or --split-output")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q (expected ollama or openai)", outputAPIFormat)
+	}
+}
+
+// validateDetailsOnly rejects --details-only with options that shape the
+// output differently
+func validateDetailsOnly() error {
+	if !detailsOnly {
+		return nil
+	}
+	if outputFormat != "json" || outputAPIFormat != "ollama" || len(outputFields) > 0 || stream || splitOutput || strings.HasSuffix(output, "/") {
+		return errors.New("details output requires --format json and can't be combined with --output-format openai, --fields, --stream or --split-output")
+	}
+	return nil
+}
+
+// validateFormat checks --format before anything is fetched, as
+// encodeOllamaResponse would only reject it once the output is written
+func validateFormat() error {
+	switch outputFormat {
+	case "json", "names", "csv":
+		return nil
+	default:
+		return fmt.Errorf("unknown --format %q (expected json, names or csv)", outputFormat)
+	}
+}
+
+// validateHistory rejects --history without an --output directory to keep
+// the files in, or with a --format other than the JSON history files hold
+func validateHistory() error {
+	if !history {
+		return nil
+	}
+	if writesToStdout() {
+		return errors.New("--history needs an --output directory for the history files")
+	}
+	if outputFormat != "json" {
+		return fmt.Errorf("--history saves JSON files and can't be combined with --format %s", outputFormat)
+	}
+	return nil
+}
+
+// validateSplitOutput rejects --split-output, or an --output ending in "/",
+// without an --output directory or with --fields or --format, which the
+// per-model JSON files don't apply
+func validateSplitOutput() error {
+	if !splitOutput && !strings.HasSuffix(output, "/") {
+		return nil
+	}
+	if writesToStdout() {
+		return errors.New("--split-output needs an --output directory for the model files")
+	}
+	if len(outputFields) > 0 || outputFormat != "json" {
+		return errors.New("--split-output saves each model's full JSON and can't be combined with --fields or --format")
+	}
+	return nil
+}
+
+// validateStream rejects --stream with options it can't honor, as the
+// stream always writes the full JSON model list to a single file
+func validateStream() error {
+	if validateResponse {
+		return errors.New("--validate needs the whole response and can't be combined with --stream")
+	}
+	if outputFormat != "json" || len(outputFields) > 0 || history || splitOutput || strings.HasSuffix(output, "/") {
+		return errors.New("streamed output requires --format json and can't be combined with --fields, --history or --split-output")
+	}
+	if webhook != "" {
+		return errors.New("--webhook posts the whole response and can't be combined with --stream")
+	}
+	return nil
+}
+
+// validateFields checks that every requested field is known
+func validateFields(fields []string) error {
+	for _, field := range fields {
+		if _, ok := modelFields[field]; !ok {
+			known := make([]string, 0, len(modelFields))
+			for name := range modelFields {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return fmt.Errorf("unknown field %q (known fields: %s)", field, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// trimFields reduces each model to only the requested fields
+func trimFields(response converter.OllamaResponse, fields []string) (map[string][]map[string]any, error) {
+	err := validateFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]map[string]any, 0, len(response.Models))
+	for _, model := range response.Models {
+		// encoding/json writes map keys sorted, so output is stable across runs
+		trimmed := make(map[string]any, len(fields))
+		for _, field := range fields {
+			trimmed[field] = modelFields[field](model)
+		}
+		models = append(models, trimmed)
+	}
+
+	return map[string][]map[string]any{"models": models}, nil
+}
+
 // printOllamaResponse prints the Ollama response to stdout
 func printOllamaResponse(response converter.OllamaResponse) error {
-	// Create pretty-printed JSON
-	jsonData, err := json.MarshalIndent(response, "", "  ")
+	return writeFormattedResponse(os.Stdout, response, "json")
+}
+
+// writeFormattedResponse writes the Ollama response to w in the given output format
+func writeFormattedResponse(w io.Writer, response converter.OllamaResponse, format string) error {
+	if format == "json" {
+		return writeJSONResponse(w, response)
+	}
+
+	data, err := encodeOllamaResponse(response, format)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return err
 	}
 
-	// Print to stdout
-	fmt.Println(string(jsonData))
-	return nil
+	_, err = w.Write(data)
+	return err
+}
+
+// writeJSONResponse encodes the response straight to w rather than into a
+// separate buffer first. The bytes match encodeOllamaResponse plus the
+// trailing newline that the text formats also end with.
+func writeJSONResponse(w io.Writer, response converter.OllamaResponse) error {
+	value, err := jsonOutputValue(response)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
 }
 
 func main() {
@@ -1,9 +1,22 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"dmr-models-convert/pkg/converter"
 )
@@ -96,3 +109,1335 @@ func TestSaveOllamaResponseInvalidPath(t *testing.T) {
 		t.Error("Expected error for invalid path, got nil")
 	}
 }
+
+func TestOutputMode(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "models.json")
+	os.WriteFile(filename, []byte("{}"), 0644)
+
+	outputMode = "0600"
+	defer func() { outputMode, outputPerm = "", defaultOutputPerm }()
+
+	if err := parseOutputMode(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// An existing file gets the requested mode too
+	err := saveOllamaResponse(converter.OllamaResponse{Models: []converter.OllamaModel{}}, filename)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %04o", info.Mode().Perm())
+	}
+
+	for _, mode := range []string{"rw", "0800", "1000", "-1"} {
+		outputMode = mode
+		if err := parseOutputMode(); err == nil {
+			t.Errorf("Expected error for --output-mode %q, got nil", mode)
+		}
+	}
+}
+
+func TestNewConverterPrefixFlags(t *testing.T) {
+	stripPrefix, addPrefix = "ai/", "team/"
+	defer func() { stripPrefix, addPrefix = "", "" }()
+
+	conv := newConverter()
+	result := conv.ConvertDMRToOllama([]converter.DMRModel{
+		{ID: "sha256:test1", Tags: []string{"ai/model1"}},
+	})
+
+	if result.Models[0].Name != "team/model1" {
+		t.Errorf("Expected model name 'team/model1', got '%s'", result.Models[0].Name)
+	}
+}
+
+func TestNewConverterNameTemplate(t *testing.T) {
+	nameTemplate = "{{.Family}}-{{.QuantizationLevel}}"
+	defer func() { nameTemplate, nameTmpl = converter.DefaultNameTemplate, nil }()
+
+	if err := parseNameTemplate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	conv := newConverter()
+	result := conv.ConvertDMRToOllama([]converter.DMRModel{
+		{ID: "sha256:test1", Tags: []string{"ai/model1"}, Config: converter.DMRConfig{Architecture: "llama", Quantization: "Q8_0"}},
+	})
+
+	if result.Models[0].Name != "llama-Q8_0" {
+		t.Errorf("Expected model name 'llama-Q8_0', got '%s'", result.Models[0].Name)
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := map[string]string{
+		"ai/smollm2:360M-F16": "ai_smollm2_360M-F16",
+		"llama3:latest":       "llama3_latest",
+		"../etc/passwd":       "etc_passwd",
+		"":                    "model",
+	}
+
+	for input, expected := range tests {
+		if result := sanitizeFilename(input); result != expected {
+			t.Errorf("sanitizeFilename(%q) = %q, expected %q", input, result, expected)
+		}
+	}
+}
+
+func TestSaveSplitOllamaResponse(t *testing.T) {
+	response := converter.OllamaResponse{
+		Models: []converter.OllamaModel{
+			{Name: "ai/model1:latest", Digest: "aaa"},
+			{Name: "ai/model2:1B", Digest: "bbb"},
+		},
+	}
+
+	dir := filepath.Join(t.TempDir(), "models")
+	err := saveSplitOllamaResponse(response, dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Expected to read output dir, got error %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(entries))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "ai_model1_latest.json"))
+	if err != nil {
+		t.Fatalf("Expected model file, got error %v", err)
+	}
+
+	var model converter.OllamaModel
+	err = json.Unmarshal(data, &model)
+	if err != nil {
+		t.Fatalf("Expected valid JSON, got error %v", err)
+	}
+
+	if model.Name != "ai/model1:latest" || model.Digest != "aaa" {
+		t.Errorf("Expected model1 contents, got %+v", model)
+	}
+}
+
+func TestRunEveryUpdatesOutput(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `[{"id": "sha256:test%d", "tags": ["model%d"]}]`, requests, requests)
+	}))
+	defer server.Close()
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	output = filepath.Join(t.TempDir(), "models.json")
+	defer func() { dmrURLs, output = savedURLs, "" }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var names []string
+	runEvery(ctx, 10*time.Millisecond, func(iteration int) {
+		err := convertAndSave()
+		if err != nil {
+			t.Errorf("Expected no error on iteration %d, got %v", iteration, err)
+		}
+
+		response, err := loadOllamaResponse(output)
+		if err != nil {
+			t.Fatalf("Expected output file on iteration %d, got error %v", iteration, err)
+		}
+		names = append(names, response.Models[0].Name)
+
+		if iteration == 2 {
+			cancel()
+		}
+	})
+
+	if len(names) != 2 || names[0] != "model1" || names[1] != "model2" {
+		t.Errorf("Expected the file to update from model1 to model2, got %v", names)
+	}
+}
+
+func TestConvertDMRFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "env1.json"), []byte(`[
+		{"id": "sha256:aaa", "tags": ["model1"], "created": 1745698622},
+		{"id": "sha256:bbb", "tags": ["model2"], "created": 1745698622}
+	]`), 0644)
+	os.WriteFile(filepath.Join(dir, "env2.json"), []byte(`[
+		{"id": "sha256:aaa", "tags": ["model1-alias"], "created": 1745698700},
+		{"id": "sha256:ccc", "tags": ["model3"], "created": 1745698622}
+	]`), 0644)
+
+	response, filesRead, err := convertDMRFiles(converter.NewConverter(), []string{filepath.Join(dir, "*.json")})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if filesRead != 2 {
+		t.Errorf("Expected 2 files read, got %d", filesRead)
+	}
+
+	if len(response.Models) != 3 {
+		t.Fatalf("Expected 3 models after dedup, got %d", len(response.Models))
+	}
+
+	// The overlapping ID is one model, created at the latest time seen
+	if response.Models[0].Name != "model1" || response.Models[0].ModifiedAt != time.Unix(1745698700, 0).Format(time.RFC3339) {
+		t.Errorf("Expected merged model1 modified at the later time, got %+v", response.Models[0])
+	}
+}
+
+func TestConvertDMRFilesMaxModels(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.json"), []byte(`[{"id": "sha256:aaa", "tags": ["model1"]}]`), 0644)
+	os.WriteFile(filepath.Join(dir, "b.json"), []byte(`[{"id": "sha256:bbb", "tags": ["model2"]}]`), 0644)
+
+	var logs bytes.Buffer
+	conv := converter.NewConverter()
+	conv.MaxModels = 1
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	response, _, err := convertDMRFiles(conv, []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(response.Models) != 1 || response.Models[0].Name != "model1" {
+		t.Errorf("Expected only model1 across both files, got %+v", response.Models)
+	}
+	if strings.Count(logs.String(), "truncating") != 1 {
+		t.Errorf("Expected one truncation warning, got logs: %s", logs.String())
+	}
+}
+
+func TestConvertDMRFilesDuplicateNames(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.json"), []byte(`[{"id": "sha256:aaa", "tags": ["ai/model1:latest"]}]`), 0644)
+	os.WriteFile(filepath.Join(dir, "b.json"), []byte(`[{"id": "sha256:bbb", "tags": ["ai/model1:latest"]}]`), 0644)
+	patterns := []string{filepath.Join(dir, "*.json")}
+
+	var logs bytes.Buffer
+	conv := converter.NewConverter()
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+	response, _, err := convertDMRFiles(conv, patterns)
+	if err != nil || len(response.Models) != 2 {
+		t.Fatalf("Expected 2 models and only a warning, got %d (%v)", len(response.Models), err)
+	}
+	if !strings.Contains(logs.String(), "share a name") {
+		t.Errorf("Expected a duplicate name warning across files, got logs: %s", logs.String())
+	}
+
+	conv.StrictDuplicates = true
+	_, _, err = convertDMRFiles(conv, patterns)
+	if !errors.Is(err, converter.ErrDuplicateName) {
+		t.Errorf("Expected a duplicate name error across files, got %v", err)
+	}
+}
+
+func TestConvertDMRFilesSynthesizeLatest(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "m.json")
+	os.WriteFile(filename, []byte(`[{"id": "sha256:aaa", "tags": ["ai/llama3:8b"]}, {"id": "sha256:bbb", "tags": ["ai/qwen3:1b"]}]`), 0644)
+
+	conv := converter.NewConverter()
+	conv.SynthesizeLatest = true
+	response, _, err := convertDMRFiles(conv, []string{filename})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var names []string
+	for _, model := range response.Models {
+		names = append(names, model.Name)
+	}
+	expected := []string{"ai/llama3:8b", "ai/qwen3:1b", "ai/llama3:latest", "ai/qwen3:latest"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected names %v, got %v", expected, names)
+	}
+}
+
+func TestConvertDMRFilesV1(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "v1.json")
+	os.WriteFile(filename, []byte(`{"models": [{"id": "sha256:aaa", "tags": ["model1"]}]}`), 0644)
+
+	dmrAPIVersion = converter.DMRAPIV1
+	defer func() { dmrAPIVersion = converter.DMRAPIAuto }()
+
+	response, filesRead, err := convertDMRFiles(newConverter(), []string{filename})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if filesRead != 1 || len(response.Models) != 1 || response.Models[0].Name != "model1" {
+		t.Errorf("Expected model1 from 1 file, got %d files and %+v", filesRead, response.Models)
+	}
+}
+
+func TestConvertDMRFilesMissing(t *testing.T) {
+	_, _, err := convertDMRFiles(converter.NewConverter(), []string{"/invalid/path/models.json"})
+	if err == nil {
+		t.Error("Expected error for missing file, got nil")
+	}
+}
+
+func TestSaveHistoryOllamaResponse(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var filenames []string
+	for i := 0; i < 4; i++ {
+		filename, err := saveHistoryOllamaResponse(converter.OllamaResponse{}, dir, start.Add(time.Duration(i)*time.Hour), 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	expected := filepath.Join(dir, "ollama-models-2025-06-01T15:00:00Z.json")
+	if filenames[3] != expected {
+		t.Errorf("Expected timestamped file '%s', got '%s'", expected, filenames[3])
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Expected to read output dir, got error %v", err)
+	}
+
+	// Only the two newest files are kept
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 files after pruning, got %d", len(entries))
+	}
+	if entries[0].Name() != filepath.Base(filenames[2]) || entries[1].Name() != filepath.Base(filenames[3]) {
+		t.Errorf("Expected newest files to be kept, got %s and %s", entries[0].Name(), entries[1].Name())
+	}
+}
+
+func TestWriteFormattedResponseNames(t *testing.T) {
+	response := converter.OllamaResponse{
+		Models: []converter.OllamaModel{
+			{Name: "ai/model1:latest"},
+			{Name: "ai/model2:1B"},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := writeFormattedResponse(&buf, response, "names")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "ai/model1:latest\nai/model2:1B\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWriteFormattedResponseUnknown(t *testing.T) {
+	err := writeFormattedResponse(&bytes.Buffer{}, converter.OllamaResponse{}, "xml")
+	if err == nil {
+		t.Error("Expected error for unknown format, got nil")
+	}
+}
+
+func TestPrintOllamaResponseStreamsJSON(t *testing.T) {
+	var models []converter.OllamaModel
+	for i := range 500 {
+		name := fmt.Sprintf("ai/model%d:latest", i)
+		models = append(models, converter.OllamaModel{Name: name, Model: name, Digest: "<digest>", Details: converter.OllamaDetails{Family: "llama"}})
+	}
+	response := converter.OllamaResponse{Models: models}
+
+	buffered, err := encodeOllamaResponse(response, "json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	savedStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() { os.Stdout = savedStdout }()
+
+	// Read concurrently, since the output is bigger than the pipe buffer
+	printed := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		printed <- data
+	}()
+
+	err = printOllamaResponse(response)
+	w.Close()
+	os.Stdout = savedStdout
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	streamed := <-printed
+	if !bytes.Equal(streamed, append(buffered, '\n')) {
+		t.Errorf("Expected streamed stdout to match buffered output\nstreamed:\n%s\nbuffered:\n%s", streamed, buffered)
+	}
+}
+
+func TestWriteFormattedResponseCSV(t *testing.T) {
+	response := converter.OllamaResponse{
+		Models: []converter.OllamaModel{
+			{
+				Name:       "ai/model1:latest",
+				ModifiedAt: "2025-01-01T00:00:00Z",
+				Size:       1024,
+				Digest:     "aaa",
+				Details: converter.OllamaDetails{
+					Family:            "llama",
+					ParameterSize:     "1B",
+					QuantizationLevel: "F16",
+				},
+			},
+			{Name: "model, with \"quotes\"", Digest: "bbb"},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := writeFormattedResponse(&buf, response, "csv")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got error %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+	}
+
+	expectedHeader := []string{"name", "family", "parameter_size", "quantization_level", "size_bytes", "modified_at", "digest"}
+	if strings.Join(records[0], ",") != strings.Join(expectedHeader, ",") {
+		t.Errorf("Expected header %v, got %v", expectedHeader, records[0])
+	}
+
+	expectedRow := []string{"ai/model1:latest", "llama", "1B", "F16", "1024", "2025-01-01T00:00:00Z", "aaa"}
+	if strings.Join(records[1], ",") != strings.Join(expectedRow, ",") {
+		t.Errorf("Expected row %v, got %v", expectedRow, records[1])
+	}
+
+	// Names containing commas and quotes survive the round trip
+	if records[2][0] != "model, with \"quotes\"" {
+		t.Errorf("Expected quoted name to round-trip, got %q", records[2][0])
+	}
+}
+
+func TestEncodeOllamaResponseFields(t *testing.T) {
+	outputFields = []string{"name", "digest", "family"}
+	defer func() { outputFields = nil }()
+
+	response := converter.OllamaResponse{
+		Models: []converter.OllamaModel{
+			{
+				Name:    "model1",
+				Model:   "model1",
+				Size:    1024,
+				Digest:  "aaa",
+				Details: converter.OllamaDetails{Family: "llama", Format: "gguf"},
+			},
+		},
+	}
+
+	data, err := encodeOllamaResponse(response, "json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var parsed struct {
+		Models []map[string]any `json:"models"`
+	}
+	err = json.Unmarshal(data, &parsed)
+	if err != nil {
+		t.Fatalf("Expected valid JSON, got error %v", err)
+	}
+
+	model := parsed.Models[0]
+	if len(model) != 3 {
+		t.Errorf("Expected only 3 keys, got %v", model)
+	}
+	if model["name"] != "model1" || model["digest"] != "aaa" || model["family"] != "llama" {
+		t.Errorf("Expected requested field values, got %v", model)
+	}
+}
+
+func TestEncodeOllamaResponseFieldsStableOrder(t *testing.T) {
+	// --fields output is map-valued, so it must not depend on map iteration order
+	outputFields = []string{"size", "name", "family", "digest", "model", "format"}
+	defer func() { outputFields = nil }()
+
+	response := converter.OllamaResponse{
+		Models: []converter.OllamaModel{
+			{Name: "model1", Model: "model1", Size: 1024, Digest: "aaa", Details: converter.OllamaDetails{Family: "llama", Format: "gguf"}},
+			{Name: "model2", Model: "model2", Size: 2048, Digest: "bbb", Details: converter.OllamaDetails{Family: "qwen", Format: "gguf"}},
+		},
+	}
+
+	first, err := encodeOllamaResponse(response, "json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		data, err := encodeOllamaResponse(response, "json")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !bytes.Equal(data, first) {
+			t.Fatalf("Expected byte-identical output on every encode, got\n%s\nthen\n%s", first, data)
+		}
+	}
+
+	// Keys are sorted regardless of the order the fields were requested in
+	if !bytes.Contains(first, []byte(`"digest": "aaa",
+      "family": "llama",
+      "format": "gguf",
+      "model": "model1",
+      "name": "model1",
+      "size": 1024`)) {
+		t.Errorf("Expected sorted keys, got\n%s", first)
+	}
+}
+
+func TestValidateFieldsUnknown(t *testing.T) {
+	err := validateFields([]string{"name", "colour"})
+	if err == nil || !strings.Contains(err.Error(), "colour") {
+		t.Errorf("Expected error naming unknown field, got %v", err)
+	}
+}
+
+func TestFetchModelsRetryOnEmpty(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+	}))
+	defer server.Close()
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	retryOnEmpty, retries, retryDelay = true, 3, time.Millisecond
+	defer func() {
+		dmrURLs = savedURLs
+		retryOnEmpty, retries, retryDelay = false, 3, 2*time.Second
+	}()
+
+	response, err := fetchModels()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+
+	if len(response.Models) != 1 || response.Models[0].Name != "model1" {
+		t.Errorf("Expected the eventual non-empty result, got %v", response.Models)
+	}
+}
+
+func TestFetchModelsRetryOnEmptyGivesUp(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	retryOnEmpty, retries, retryDelay = true, 2, time.Millisecond
+	defer func() {
+		dmrURLs = savedURLs
+		retryOnEmpty, retries, retryDelay = false, 3, 2*time.Second
+	}()
+
+	response, err := fetchModels()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if requests != 3 || len(response.Models) != 0 {
+		t.Errorf("Expected 3 attempts ending empty, got %d requests and %d models", requests, len(response.Models))
+	}
+}
+
+func TestStreamConvertAndSave(t *testing.T) {
+	dir := t.TempDir()
+	dmrFile := filepath.Join(dir, "dmr.json")
+	os.WriteFile(dmrFile, []byte(`[
+		{"id": "sha256:aaa", "tags": ["model1"], "created": 1745698622, "config": {"architecture": "llama", "size": "1 GiB"}},
+		{"id": "sha256:bbb", "tags": ["model2"], "created": 1745698622, "config": {"architecture": "qwen3", "size": "2 GiB"}}
+	]`), 0644)
+
+	dmrFiles = []string{dmrFile}
+	stream = true
+	output = filepath.Join(dir, "streamed.json")
+	defer func() { dmrFiles, stream, output = nil, false, "" }()
+
+	err := convertAndSave()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	streamed, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Expected streamed file, got error %v", err)
+	}
+
+	// The streamed file should match the regular buffered output
+	buffered, err := encodeOllamaResponse(converter.NewConverter().ConvertDMRToOllama([]converter.DMRModel{
+		{ID: "sha256:aaa", Tags: []string{"model1"}, Created: 1745698622, Config: converter.DMRConfig{Architecture: "llama", Size: "1 GiB"}},
+		{ID: "sha256:bbb", Tags: []string{"model2"}, Created: 1745698622, Config: converter.DMRConfig{Architecture: "qwen3", Size: "2 GiB"}},
+	}), "json")
+	if err != nil {
+		t.Fatalf("Expected no error encoding, got %v", err)
+	}
+
+	if string(streamed) != string(buffered) {
+		t.Errorf("Expected streamed output to match buffered output\nstreamed:\n%s\nbuffered:\n%s", streamed, buffered)
+	}
+}
+
+func TestStreamConvertAndSaveMergesFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.json"), []byte(`[{"id": "sha256:aaa", "tags": ["model1"], "created": 1}]`), 0644)
+	os.WriteFile(filepath.Join(dir, "b.json"), []byte(`[{"id": "sha256:aaa", "tags": ["model1-alias"], "created": 2}, {"id": "sha256:bbb", "tags": ["model2"]}]`), 0644)
+
+	dmrFiles = []string{filepath.Join(dir, "*.json")}
+	stream = true
+	output = filepath.Join(dir, "streamed.out")
+	defer func() { dmrFiles, stream, output = nil, false, "" }()
+
+	if err := convertAndSave(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	streamed, _ := os.ReadFile(output)
+
+	// Streaming merges the files by digest like the buffered conversion
+	response, _, err := convertDMRFiles(converter.NewConverter(), dmrFiles)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	buffered, _ := encodeOllamaResponse(response, "json")
+	if string(streamed) != string(buffered) {
+		t.Errorf("Expected streamed output to match buffered output\nstreamed:\n%s\nbuffered:\n%s", streamed, buffered)
+	}
+}
+
+func TestStreamFailOnEmpty(t *testing.T) {
+	dir := t.TempDir()
+	dmrFile := filepath.Join(dir, "dmr.json")
+	os.WriteFile(dmrFile, []byte(`[{"config": {"format": "gguf"}}]`), 0644)
+	output = filepath.Join(dir, "streamed.json")
+	os.WriteFile(output, []byte("previous"), 0644)
+
+	dmrFiles = []string{dmrFile}
+	stream, skipInvalid, failOnEmpty = true, true, true
+	defer func() { dmrFiles, stream, skipInvalid, failOnEmpty, output = nil, false, false, false, "" }()
+
+	err := convertAndSave()
+	if !errors.Is(err, errNoModels) {
+		t.Fatalf("Expected errNoModels, got %v", err)
+	}
+
+	// The previous output is kept when nothing was converted
+	previous, _ := os.ReadFile(output)
+	if string(previous) != "previous" {
+		t.Errorf("Expected the previous output to be kept, got %s", previous)
+	}
+	if _, err := os.Stat(output + ".tmp"); err == nil {
+		t.Error("Expected the temporary stream file to be removed")
+	}
+}
+
+func TestConvertAndSaveDashWritesStdout(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "dmr.json"), []byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`), 0644)
+
+	dmrFiles = []string{filepath.Join(dir, "dmr.json")}
+	output = "-"
+	savedStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() {
+		dmrFiles, output = nil, ""
+		os.Stdout = savedStdout
+	}()
+
+	err := convertAndSave()
+	w.Close()
+	os.Stdout = savedStdout
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	printed, _ := io.ReadAll(r)
+	var response converter.OllamaResponse
+	if err := json.Unmarshal(printed, &response); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", printed, err)
+	}
+	if len(response.Models) != 1 || response.Models[0].Name != "model1" {
+		t.Errorf("Expected model1 on stdout, got %+v", response.Models)
+	}
+	if _, err := os.Stat("-"); err == nil {
+		t.Error("Expected no file named '-' to be created")
+	}
+}
+
+func TestSelectOutputWriter(t *testing.T) {
+	defer func() { output, history, splitOutput, outputFormat = "", false, false, "json" }()
+
+	tests := []struct {
+		output      string
+		history     bool
+		splitOutput bool
+		expected    outputWriter
+	}{
+		{"", false, false, streamOutput{w: os.Stdout, format: "names"}},
+		{"models.json", false, false, fileOutput{filename: "models.json", format: "names"}},
+		{"models/", false, false, splitDirOutput{dir: "models/"}},
+		{"models", false, true, splitDirOutput{dir: "models"}},
+		{"models", true, true, historyOutput{dir: "models", keep: keep}},
+		{"-", true, true, streamOutput{w: os.Stdout, format: "names"}},
+	}
+
+	outputFormat = "names"
+	for _, test := range tests {
+		output, history, splitOutput = test.output, test.history, test.splitOutput
+		if result := selectOutputWriter(); result != test.expected {
+			t.Errorf("For output %q (history %v, split %v) expected %#v, got %#v", test.output, test.history, test.splitOutput, test.expected, result)
+		}
+	}
+}
+
+func TestFetchModelsExpandsEnv(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+	}))
+	defer server.Close()
+
+	host, port, _ := strings.Cut(strings.TrimPrefix(server.URL, "http://"), ":")
+	t.Setenv("DMR_PORT", port)
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{"http://" + host + ":${DMR_PORT}/models"}
+	defer func() { dmrURLs = savedURLs }()
+
+	response, err := fetchModels()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(response.Models) != 1 || response.Models[0].Name != "model1" {
+		t.Errorf("Expected model1 from the expanded URL, got %v", response.Models)
+	}
+}
+
+func TestExpandDMRURLs(t *testing.T) {
+	t.Setenv("DMR_HOST", "dmr")
+
+	result := expandDMRURLs([]string{
+		"http://${DMR_HOST}:12434/models",
+		"http://${DMR_UNSET_FOR_TEST}/models",
+		"http://localhost:12434/models",
+	})
+
+	expected := []string{
+		"http://dmr:12434/models",
+		"http://${DMR_UNSET_FOR_TEST}/models",
+		"http://localhost:12434/models",
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("Expected '%s', got '%s'", expected[i], result[i])
+		}
+	}
+}
+
+func TestWriteErrorJSON(t *testing.T) {
+	dmrFiles = []string{filepath.Join(t.TempDir(), "missing.json")}
+	defer func() { dmrFiles = nil }()
+
+	// Force a failure by reading a DMR file that doesn't exist
+	err := convertAndSave()
+	if err == nil {
+		t.Fatal("Expected error for missing DMR file, got nil")
+	}
+
+	var buf bytes.Buffer
+	writeError(&buf, err, 1)
+	if !strings.HasPrefix(buf.String(), "Error converting DMR files: ") {
+		t.Errorf("Expected plain error text by default, got %q", buf.String())
+	}
+
+	jsonErrors = true
+	defer func() { jsonErrors = false }()
+
+	buf.Reset()
+	writeError(&buf, err, 1)
+
+	var parsed map[string]any
+	err = json.Unmarshal(buf.Bytes(), &parsed)
+	if err != nil {
+		t.Fatalf("Expected JSON error output, got %q: %v", buf.String(), err)
+	}
+
+	if len(parsed) != 2 || parsed["code"] != float64(1) {
+		t.Errorf("Expected only error and code 1, got %v", parsed)
+	}
+
+	message, _ := parsed["error"].(string)
+	if !strings.HasPrefix(message, "converting DMR files: ") {
+		t.Errorf("Expected error message, got %q", message)
+	}
+}
+
+func TestAuthTokenFile(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(tokenFile, []byte("secret-token\n"), 0600)
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	authTokenFile = tokenFile
+	defer func() {
+		dmrURLs = savedURLs
+		authTokenFile, authToken = "", ""
+	}()
+
+	if err := loadAuthToken(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err := fetchModels()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if authorization != "Bearer secret-token" {
+		t.Errorf("Expected 'Bearer secret-token', got '%s'", authorization)
+	}
+}
+
+func TestAuthTokenFileUnreadable(t *testing.T) {
+	authTokenFile = filepath.Join(t.TempDir(), "missing")
+	defer func() { authTokenFile, authToken = "", "" }()
+
+	err := loadAuthToken()
+	if err == nil || !strings.Contains(err.Error(), "auth token file") {
+		t.Errorf("Expected auth token file error, got %v", err)
+	}
+}
+
+func TestFamilyMapFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"families.yaml": "acme-net: acme\nLlama: custom-llama\n",
+		"families.json": `{"acme-net": "acme", "Llama": "custom-llama"}`,
+	}
+	defer func() { familyMapFile, familyMap = "", nil }()
+
+	for name, content := range files {
+		familyMapFile = filepath.Join(dir, name)
+		os.WriteFile(familyMapFile, []byte(content), 0644)
+
+		if err := loadFamilyMap(); err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+
+		result := newConverter().ConvertDMRToOllama([]converter.DMRModel{
+			{ID: "sha256:test1", Tags: []string{"model1"}, Config: converter.DMRConfig{Architecture: "acme-net"}},
+			{ID: "sha256:test2", Tags: []string{"model2"}, Config: converter.DMRConfig{Architecture: "llama"}},
+		})
+		if result.Models[0].Details.Family != "acme" || result.Models[1].Details.Family != "custom-llama" {
+			t.Errorf("%s: expected families acme and custom-llama, got '%s' and '%s'", name, result.Models[0].Details.Family, result.Models[1].Details.Family)
+		}
+	}
+}
+
+func TestFamilyMapFileMalformed(t *testing.T) {
+	dir := t.TempDir()
+	defer func() { familyMapFile, familyMap = "", nil }()
+
+	for name, content := range map[string]string{
+		"list.yaml":   "- acme\n- llama\n",
+		"nested.yaml": "acme:\n  family: acme\n",
+		"empty.yaml":  "acme: \"\"\n",
+		"bad.json":    `{"acme": `,
+	} {
+		familyMapFile = filepath.Join(dir, name)
+		os.WriteFile(familyMapFile, []byte(content), 0644)
+
+		err := loadFamilyMap()
+		if err == nil || !strings.Contains(err.Error(), "malformed family map file") {
+			t.Errorf("%s: expected malformed family map error, got %v", name, err)
+		}
+	}
+}
+
+func TestHeaderFlag(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+	}))
+	defer server.Close()
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	headers = []string{"X-Feature: beta", "X-Trace-Id:abc123", "X-Feature: tools"}
+	defer func() {
+		dmrURLs = savedURLs
+		headers, dmrHeaders = nil, nil
+	}()
+
+	if err := parseHeaders(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err := fetchModels()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if features := received.Values("X-Feature"); len(features) != 2 || features[0] != "beta" || features[1] != "tools" {
+		t.Errorf("Expected X-Feature values [beta tools], got %v", features)
+	}
+	if traceID := received.Get("X-Trace-Id"); traceID != "abc123" {
+		t.Errorf("Expected X-Trace-Id 'abc123', got '%s'", traceID)
+	}
+}
+
+func TestHeaderFlagMalformed(t *testing.T) {
+	defer func() { headers, dmrHeaders = nil, nil }()
+
+	for _, header := range []string{"X-Feature", ": value", "Bad Name: value"} {
+		headers = []string{header}
+		if err := parseHeaders(); err == nil {
+			t.Errorf("Expected error for header %q, got nil", header)
+		}
+	}
+}
+
+func TestSinceFlag(t *testing.T) {
+	now := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"id": "sha256:old", "tags": ["old"], "created": %d}, {"id": "sha256:new", "tags": ["new"], "created": %d}]`,
+			now.Add(-48*time.Hour).Unix(), now.Add(-time.Hour).Unix())
+	}))
+	defer server.Close()
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	since = "24h"
+	defer func() {
+		dmrURLs = savedURLs
+		since, sinceTime = "", time.Time{}
+	}()
+
+	if err := parseSince(now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	response, err := fetchModels()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(response.Models) != 1 || response.Models[0].Name != "new" {
+		t.Errorf("Expected only the new model, got %+v", response.Models)
+	}
+
+	since = "last week"
+	if err := parseSince(now); err == nil {
+		t.Error("Expected error for invalid --since, got nil")
+	}
+}
+
+func TestSinceEachRun(t *testing.T) {
+	now := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"id": "sha256:old", "tags": ["old"], "created": %d}, {"id": "sha256:new", "tags": ["new"], "created": %d}]`,
+			now.Add(-48*time.Hour).Unix(), now.Add(-time.Hour).Unix())
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	output = filepath.Join(dir, "models.json")
+	since = "24h"
+	defer func() {
+		dmrURLs, output = savedURLs, ""
+		since, sinceTime = "", time.Time{}
+	}()
+
+	// A cutoff left over from a run long ago would let the old model through
+	if err := parseSince(now.Add(-72 * time.Hour)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := convertAndSave(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Expected output file, got error %v", err)
+	}
+	var response converter.OllamaResponse
+	json.Unmarshal(data, &response)
+	if len(response.Models) != 1 || response.Models[0].Name != "new" {
+		t.Errorf("Expected only the new model, got %+v", response.Models)
+	}
+}
+
+func TestDumpDMR(t *testing.T) {
+	dmrBody := `{"api_version": "v1", "models": [{"id": "sha256:aaa", "tags": ["model1"], "created": "2025-04-26T20:17:02Z", "config": {"architecture": "llama", "size": 1073741824, "rope_theta": 500000}}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(dmrBody))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	output = filepath.Join(dir, "models.json")
+	dumpDMR = filepath.Join(dir, "dmr.json")
+	defer func() { dmrURLs, output, dumpDMR = savedURLs, "", "" }()
+
+	err := convertAndSave()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("Expected converted output file, got error %v", err)
+	}
+
+	dumped, err := os.ReadFile(dumpDMR)
+	if err != nil {
+		t.Fatalf("Expected DMR dump file, got error %v", err)
+	}
+
+	// The dump is DMR's response as it was sent, unknown fields and all
+	var expected bytes.Buffer
+	json.Indent(&expected, []byte(dmrBody), "", "  ")
+	if string(dumped) != expected.String() {
+		t.Errorf("Expected the raw DMR response in the dump, got %s", dumped)
+	}
+}
+
+func TestDumpDMRMultipleServers(t *testing.T) {
+	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "sha256:aaa", "tags": ["model1"]}]`))
+	}))
+	defer server1.Close()
+	server2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "sha256:bbb", "tags": ["model2"]}]`))
+	}))
+	defer server2.Close()
+
+	dir := t.TempDir()
+	savedURLs := dmrURLs
+	dmrURLs = []string{server1.URL, server2.URL}
+	output = filepath.Join(dir, "models.json")
+	dumpDMR = filepath.Join(dir, "dmr.json")
+	defer func() { dmrURLs, output, dumpDMR = savedURLs, "", "" }()
+
+	if err := convertAndSave(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, name := range []string{"dmr-1.json", "dmr-2.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected DMR dump file %s, got error %v", name, err)
+		}
+	}
+	if _, err := os.Stat(dumpDMR); err == nil {
+		t.Errorf("Expected numbered dump files only, found %s", dumpDMR)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "server1.json"), []byte(`[{"id": "sha256:aaa", "tags": ["model1"]}]`), 0644)
+	os.WriteFile(filepath.Join(dir, "server2.json"), []byte(`[{"id": "sha256:bbb", "tags": ["model2"]}]`), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`not DMR JSON`), 0644)
+
+	// Any fetch would fail, so models must come from the replay files
+	savedURLs := dmrURLs
+	dmrURLs = []string{"http://127.0.0.1:0/models"}
+	replayDir = dir
+	defer func() { dmrURLs, replayDir, dmrFiles = savedURLs, "", nil }()
+
+	if err := loadReplayFiles(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	response, err := fetchModels()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(response.Models) != 2 || response.Models[0].Name != "model1" || response.Models[1].Name != "model2" {
+		t.Errorf("Expected model1 and model2 from the replay files, got %v", response.Models)
+	}
+}
+
+func TestReplayEmptyDir(t *testing.T) {
+	replayDir = t.TempDir()
+	defer func() { replayDir, dmrFiles = "", nil }()
+
+	err := loadReplayFiles()
+	if err == nil || !strings.Contains(err.Error(), "no .json files") {
+		t.Errorf("Expected no .json files error, got %v", err)
+	}
+}
+
+func TestConvertAndSaveOpenAIFormat(t *testing.T) {
+	dir := t.TempDir()
+	dmrFile := filepath.Join(dir, "dmr.json")
+	os.WriteFile(dmrFile, []byte(`[{"id": "sha256:aaa", "tags": ["ai/model1:latest"], "created": 1745698622}]`), 0644)
+
+	dmrFiles = []string{dmrFile}
+	output = filepath.Join(dir, "models.json")
+	outputAPIFormat = "openai"
+	defer func() { dmrFiles, output, outputAPIFormat = nil, "", "ollama" }()
+
+	err := convertAndSave()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	jsonData, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Expected output file, got error %v", err)
+	}
+
+	var list converter.OpenAIModelList
+	if err := json.Unmarshal(jsonData, &list); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	expected := converter.OpenAIModel{ID: "ai/model1:latest", Object: "model", Created: 1745698622, OwnedBy: "docker"}
+	if list.Object != "list" || len(list.Data) != 1 || list.Data[0] != expected {
+		t.Errorf("Expected an OpenAI list with %+v, got %s", expected, jsonData)
+	}
+}
+
+func TestConvertAndSaveDetailsOnly(t *testing.T) {
+	dir := t.TempDir()
+	dmrFile := filepath.Join(dir, "dmr.json")
+	os.WriteFile(dmrFile, []byte(`[{"id": "sha256:aaa", "tags": ["ai/model1:latest"], "config": {"architecture": "llama", "parameters": "1B", "quantization": "Q4_K_M", "size": "1 GiB"}}]`), 0644)
+
+	dmrFiles = []string{dmrFile}
+	output = filepath.Join(dir, "details.json")
+	detailsOnly = true
+	defer func() { dmrFiles, output, detailsOnly = nil, "", false }()
+
+	err := convertAndSave()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	jsonData, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Expected output file, got error %v", err)
+	}
+
+	// Decode loosely so any extra key in the compact shape is caught
+	var details []map[string]any
+	if err := json.Unmarshal(jsonData, &details); err != nil {
+		t.Fatalf("Expected a JSON array, got %s: %v", jsonData, err)
+	}
+	expected := map[string]any{"name": "ai/model1:latest", "family": "llama", "parameter_size": "1B", "quantization_level": "Q4_K_M"}
+	if len(details) != 1 || !reflect.DeepEqual(details[0], expected) {
+		t.Errorf("Expected [%v], got %s", expected, jsonData)
+	}
+
+	fields := outputFields
+	outputFields = []string{"name"}
+	defer func() { outputFields = fields }()
+	if err := convertAndSave(); err == nil {
+		t.Error("Expected error combining --details-only with --fields, got nil")
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	defer func() { outputAPIFormat, outputFormat = "ollama", "json" }()
+
+	outputAPIFormat = "anthropic"
+	if err := validateOutputFormat(); err == nil {
+		t.Error("Expected error for unknown output format, got nil")
+	}
+
+	outputAPIFormat, outputFormat = "openai", "csv"
+	if err := validateOutputFormat(); err == nil {
+		t.Error("Expected error for openai output with --format csv, got nil")
+	}
+}
+
+func TestValidateStream(t *testing.T) {
+	defer func() {
+		outputFormat, outputFields, history, splitOutput, output, webhook = "json", nil, false, false, "", ""
+	}()
+
+	if err := validateStream(); err != nil {
+		t.Errorf("Expected no error for streamed JSON, got %v", err)
+	}
+
+	tests := map[string]func(){
+		"--format csv":    func() { outputFormat = "csv" },
+		"--format names":  func() { outputFormat = "names" },
+		"--fields":        func() { outputFields = []string{"name"} },
+		"--history":       func() { history = true },
+		"--split-output":  func() { splitOutput = true },
+		"output dir path": func() { output = "models/" },
+		"--webhook":       func() { webhook = "http://localhost:8080/hook" },
+	}
+	for name, set := range tests {
+		outputFormat, outputFields, history, splitOutput, output, webhook = "json", nil, false, false, "", ""
+		set()
+		if err := validateStream(); err == nil {
+			t.Errorf("%s: expected error with --stream, got nil", name)
+		}
+	}
+}
+
+func TestProgressReporter(t *testing.T) {
+	var urls []string
+	for range 3 {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+		}))
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+
+	var progress bytes.Buffer
+	conv := newConverter()
+	conv.Progress = progressReporter(&progress)
+
+	_, err := conv.FetchDMRModelsFromURLs(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "Fetched 1/3 DMR servers\nFetched 2/3 DMR servers\nFetched 3/3 DMR servers\n"
+	if progress.String() != expected {
+		t.Errorf("Expected progress lines %q, got %q", expected, progress.String())
+	}
+
+	// A single server reports nothing
+	progress.Reset()
+	conv.FetchDMRModelsFromURLs(context.Background(), urls[:1])
+	if progress.Len() != 0 {
+		t.Errorf("Expected no progress for one server, got %q", progress.String())
+	}
+}
+
+func TestConvertAndSaveValidate(t *testing.T) {
+	dir := t.TempDir()
+	dmrFile := filepath.Join(dir, "dmr.json")
+	// No architecture and no recognizable name leaves the family empty
+	os.WriteFile(dmrFile, []byte(`[{"id": "sha256:aaa", "tags": ["mystery"], "created": 1745698622, "config": {"format": "gguf"}}]`), 0644)
+
+	dmrFiles = []string{dmrFile}
+	output = filepath.Join(dir, "models.json")
+	validateResponse = true
+	defer func() { dmrFiles, output, validateResponse = nil, "", false }()
+
+	err := convertAndSave()
+	if !errors.Is(err, converter.ErrInvalidResponse) || !strings.Contains(err.Error(), "empty family") {
+		t.Fatalf("Expected empty family violation, got %v", err)
+	}
+
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("Expected no output file for an invalid response, got %v", err)
+	}
+}
+
+func TestConvertRejectsArgs(t *testing.T) {
+	// "--strict-size skip" leaves "skip" as an argument, with the flag in fail mode
+	if err := convertCmd.ValidateArgs([]string{"skip"}); err == nil {
+		t.Error("Expected error for a stray argument, got nil")
+	}
+	if err := statsCmd.ValidateArgs([]string{"skip"}); err == nil {
+		t.Error("Expected error for a stray stats argument, got nil")
+	}
+}
+
+func TestValidateHistory(t *testing.T) {
+	defer func() { history, output, outputFormat = false, "", "json" }()
+
+	history, output = true, t.TempDir()
+	if err := validateHistory(); err != nil {
+		t.Errorf("Expected no error for a history directory, got %v", err)
+	}
+
+	output = ""
+	if err := validateHistory(); err == nil {
+		t.Error("Expected error for --history without --output, got nil")
+	}
+
+	output, outputFormat = t.TempDir(), "csv"
+	if err := validateHistory(); err == nil {
+		t.Error("Expected error for --history with --format csv, got nil")
+	}
+}
+
+func TestValidateSplitOutput(t *testing.T) {
+	defer func() { splitOutput, output, outputFields, outputFormat = false, "", nil, "json" }()
+
+	splitOutput, output = true, t.TempDir()
+	if err := validateSplitOutput(); err != nil {
+		t.Errorf("Expected no error for a split output directory, got %v", err)
+	}
+
+	output = ""
+	if err := validateSplitOutput(); err == nil {
+		t.Error("Expected error for --split-output without --output, got nil")
+	}
+
+	output, outputFields = t.TempDir(), []string{"name"}
+	if err := validateSplitOutput(); err == nil {
+		t.Error("Expected error for --split-output with --fields, got nil")
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	defer func() { outputFormat = "json" }()
+
+	for _, format := range []string{"json", "names", "csv"} {
+		outputFormat = format
+		if err := validateFormat(); err != nil {
+			t.Errorf("Expected no error for --format %s, got %v", format, err)
+		}
+	}
+
+	outputFormat = "yaml"
+	if err := validateFormat(); err == nil {
+		t.Error("Expected error for --format yaml, got nil")
+	}
+}
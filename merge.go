@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"dmr-models-convert/pkg/converter"
+
+	"github.com/spf13/cobra"
+)
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge <file.json>...",
+	Short: "Merge several converted Ollama JSON files",
+	Long: `Merge the model lists of several Ollama JSON files produced by convert.
+Arguments may be file paths or glob patterns. Models with the same digest
+are combined: their names are merged into tags and the most recent
+modified_at is kept.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filenames, err := expandGlobs(args)
+		if err != nil {
+			return fmt.Errorf("expanding file patterns: %w", err)
+		}
+
+		var responses []converter.OllamaResponse
+		for _, filename := range filenames {
+			response, err := loadOllamaResponse(filename)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", filename, err)
+			}
+			responses = append(responses, response)
+		}
+
+		merged := converter.MergeResponses(responses...)
+
+		if !writesToStdout() {
+			err = saveOllamaResponse(merged, output)
+			if err != nil {
+				return outputError{fmt.Errorf("saving output file: %w", err)}
+			}
+			fmt.Printf("Merged %d files into %d models and saved to: %s\n", len(filenames), len(merged.Models), output)
+		} else {
+			err = printOllamaResponse(merged)
+			if err != nil {
+				return outputError{fmt.Errorf("printing JSON: %w", err)}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+}
+
+// expandGlobs expands glob patterns into file names. Patterns without
+// matches are kept as-is so that reading them reports a clear error.
+func expandGlobs(patterns []string) ([]string, error) {
+	var filenames []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			filenames = append(filenames, pattern)
+			continue
+		}
+		filenames = append(filenames, matches...)
+	}
+	return filenames, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dmr-models-convert/pkg/converter"
+)
+
+func TestExpandGlobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json"} {
+		err := saveOllamaResponse(converter.OllamaResponse{}, filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected no error saving, got %v", err)
+		}
+	}
+
+	filenames, err := expandGlobs([]string{filepath.Join(dir, "*.json"), "missing.json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(filenames) != 3 {
+		t.Fatalf("Expected 3 file names, got %v", filenames)
+	}
+
+	// Patterns without matches are passed through
+	if filenames[2] != "missing.json" {
+		t.Errorf("Expected unmatched pattern to be kept, got '%s'", filenames[2])
+	}
+}
+
+func TestMergeFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	second := filepath.Join(dir, "second.json")
+
+	saveOllamaResponse(converter.OllamaResponse{Models: []converter.OllamaModel{
+		{Name: "model1", Digest: "aaa", ModifiedAt: "2025-01-01T00:00:00Z"},
+	}}, first)
+	saveOllamaResponse(converter.OllamaResponse{Models: []converter.OllamaModel{
+		{Name: "model1-alias", Digest: "aaa", ModifiedAt: "2025-03-01T00:00:00Z"},
+		{Name: "model2", Digest: "bbb", ModifiedAt: "2025-01-01T00:00:00Z"},
+	}}, second)
+
+	output = filepath.Join(dir, "merged.json")
+	defer func() { output = "" }()
+
+	err := mergeCmd.RunE(mergeCmd, []string{filepath.Join(dir, "*.json")})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	merged, err := loadOllamaResponse(output)
+	if err != nil {
+		t.Fatalf("Expected merged file, got error %v", err)
+	}
+
+	if len(merged.Models) != 2 {
+		t.Fatalf("Expected 2 models after dedup, got %d", len(merged.Models))
+	}
+
+	if merged.Models[0].ModifiedAt != "2025-03-01T00:00:00Z" {
+		t.Errorf("Expected most recent modified_at, got '%s'", merged.Models[0].ModifiedAt)
+	}
+}
+
+func TestMergeCmdMissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	err := mergeCmd.RunE(mergeCmd, []string{missing})
+	if err == nil {
+		t.Fatal("Expected error for missing file, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "reading "+missing) {
+		t.Errorf("Expected error naming the missing file, got %v", err)
+	}
+}
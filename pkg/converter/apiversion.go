@@ -0,0 +1,38 @@
+package converter
+
+import "fmt"
+
+// DMR API versions accepted by Converter.APIVersion. Version v0 returns a
+// bare array of models; v1 wraps them in a {"models": [...]} object that
+// may carry pagination. Auto, the default, accepts either.
+const (
+	DMRAPIAuto = "auto"
+	DMRAPIV0   = "v0"
+	DMRAPIV1   = "v1"
+)
+
+// ValidateAPIVersion checks that version is a known DMR API version
+func ValidateAPIVersion(version string) error {
+	switch version {
+	case "", DMRAPIAuto, DMRAPIV0, DMRAPIV1:
+		return nil
+	default:
+		return fmt.Errorf("unknown DMR API version %q (expected %s, %s or %s)", version, DMRAPIAuto, DMRAPIV0, DMRAPIV1)
+	}
+}
+
+// checkAPIVersion reports an error when a DMR page's shape, an enveloped
+// v1 object or a bare v0 array, doesn't match the configured APIVersion
+func (c *Converter) checkAPIVersion(enveloped bool) error {
+	if err := ValidateAPIVersion(c.APIVersion); err != nil {
+		return err
+	}
+
+	switch {
+	case c.APIVersion == DMRAPIV0 && enveloped:
+		return fmt.Errorf("%w: expected a DMR v0 model array, got an object", ErrParse)
+	case c.APIVersion == DMRAPIV1 && !enveloped:
+		return fmt.Errorf("%w: expected a DMR v1 {\"models\": [...]} object", ErrParse)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIVersionDecoders(t *testing.T) {
+	payloads := map[string]string{
+		DMRAPIV0: `[{"id": "sha256:test1", "tags": ["model1"]}]`,
+		DMRAPIV1: `{"models": [{"id": "sha256:test1", "tags": ["model1"]}]}`,
+	}
+
+	for payloadVersion, payload := range payloads {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(payload))
+		}))
+
+		for _, version := range []string{"", DMRAPIAuto, DMRAPIV0, DMRAPIV1} {
+			conv := NewConverter()
+			conv.APIVersion = version
+			models, err := conv.FetchDMRModels(server.URL)
+
+			matches := version == "" || version == DMRAPIAuto || version == payloadVersion
+			if matches && (err != nil || len(models) != 1 || models[0].Tags[0] != "model1") {
+				t.Errorf("%s payload with version %q: expected model1, got %v (%v)", payloadVersion, version, models, err)
+			}
+			if !matches && !errors.Is(err, ErrParse) {
+				t.Errorf("%s payload with version %q: expected parse error, got %v", payloadVersion, version, err)
+			}
+		}
+
+		server.Close()
+	}
+}
+
+func TestParseDMRModelsAPIVersions(t *testing.T) {
+	payloads := map[string]string{
+		DMRAPIV0: `[{"id": "sha256:test1", "tags": ["model1"]}]`,
+		DMRAPIV1: `{"models": [{"id": "sha256:test1", "tags": ["model1"]}], "next": "/models?page=2"}`,
+	}
+
+	for payloadVersion, payload := range payloads {
+		for _, version := range []string{"", DMRAPIAuto, DMRAPIV0, DMRAPIV1} {
+			conv := NewConverter()
+			conv.APIVersion = version
+			models, err := conv.ParseDMRModels([]byte(payload))
+
+			matches := version == "" || version == DMRAPIAuto || version == payloadVersion
+			if matches && (err != nil || len(models) != 1 || models[0].Tags[0] != "model1") {
+				t.Errorf("%s payload with version %q: expected model1, got %v (%v)", payloadVersion, version, models, err)
+			}
+			if !matches && !errors.Is(err, ErrParse) {
+				t.Errorf("%s payload with version %q: expected parse error, got %v", payloadVersion, version, err)
+			}
+		}
+	}
+}
+
+func TestValidateAPIVersion(t *testing.T) {
+	for _, version := range []string{"", DMRAPIAuto, DMRAPIV0, DMRAPIV1} {
+		if err := ValidateAPIVersion(version); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", version, err)
+		}
+	}
+
+	if err := ValidateAPIVersion("v2"); err == nil {
+		t.Error("Expected error for unknown version v2, got nil")
+	}
+}
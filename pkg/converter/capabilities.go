@@ -0,0 +1,69 @@
+package converter
+
+import "strings"
+
+// Capabilities reported in the /api/show "capabilities" array
+const (
+	CapabilityCompletion = "completion"
+	CapabilityEmbedding  = "embedding"
+	CapabilityVision     = "vision"
+	CapabilityTools      = "tools"
+)
+
+// embeddingArchitectures are GGUF architectures only used by embedding models
+var embeddingArchitectures = map[string]bool{
+	"bert":         true,
+	"nomic-bert":   true,
+	"jina-bert-v2": true,
+	"xlm-roberta":  true,
+}
+
+// visionArchitectures are GGUF architectures and families of multimodal
+// models that accept images
+var visionArchitectures = map[string]bool{
+	"llava":     true,
+	"moondream": true,
+	"mllama":    true,
+	"qwen2vl":   true,
+	"qwen25vl":  true,
+	"gemma3":    true,
+	"mistral3":  true,
+}
+
+// isEmbeddingModel reports whether DMR marks the model as an embedding model,
+// through its type or capabilities, or it uses an embedding-only architecture
+func isEmbeddingModel(dmrModel DMRModel) bool {
+	if strings.EqualFold(dmrModel.Type, CapabilityEmbedding) {
+		return true
+	}
+	for _, capability := range dmrModel.Capabilities {
+		if strings.EqualFold(capability, CapabilityEmbedding) {
+			return true
+		}
+	}
+	return embeddingArchitectures[strings.ToLower(dmrModel.Config.Architecture)]
+}
+
+// modelCapabilities returns the Ollama capabilities of a DMR model. Explicit
+// DMR capabilities are used as-is; otherwise they are inferred from the
+// architecture, defaulting to completion only.
+func modelCapabilities(dmrModel DMRModel) []string {
+	if len(dmrModel.Capabilities) > 0 {
+		capabilities := make([]string, len(dmrModel.Capabilities))
+		for i, capability := range dmrModel.Capabilities {
+			capabilities[i] = strings.ToLower(capability)
+		}
+		return capabilities
+	}
+
+	if isEmbeddingModel(dmrModel) {
+		return []string{CapabilityEmbedding}
+	}
+
+	capabilities := []string{CapabilityCompletion}
+	family, _ := determineFamily(dmrModel.Config.Architecture)
+	if visionArchitectures[strings.ToLower(dmrModel.Config.Architecture)] || visionArchitectures[family] {
+		capabilities = append(capabilities, CapabilityVision)
+	}
+	return capabilities
+}
@@ -0,0 +1,131 @@
+package converter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestIsEmbeddingModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    DMRModel
+		expected bool
+	}{
+		{"type", DMRModel{Type: "embedding"}, true},
+		{"capabilities", DMRModel{Capabilities: []string{"Embedding"}}, true},
+		{"architecture", DMRModel{Config: DMRConfig{Architecture: "nomic-bert"}}, true},
+		{"completion", DMRModel{Config: DMRConfig{Architecture: "llama"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := isEmbeddingModel(tt.model); result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertDMRToShowEmbeddingCapability(t *testing.T) {
+	var dmrModels []DMRModel
+	err := json.Unmarshal([]byte(`[{
+		"id": "sha256:embed1",
+		"tags": ["ai/mxbai-embed-large:latest"],
+		"type": "embedding",
+		"config": {"format": "gguf", "architecture": "bert", "parameters": "334.09 M"}
+	}]`), &dmrModels)
+	if err != nil {
+		t.Fatalf("Expected valid DMR JSON, got %v", err)
+	}
+
+	conv := NewConverter()
+	show := conv.ConvertDMRToShow(dmrModels[0])
+	if len(show.Capabilities) != 1 || show.Capabilities[0] != CapabilityEmbedding {
+		t.Errorf("Expected capabilities [embedding], got %v", show.Capabilities)
+	}
+
+	jsonData, _ := json.Marshal(show)
+	if !strings.Contains(string(jsonData), `"capabilities":["embedding"]`) {
+		t.Errorf("Expected capabilities in JSON, got %s", jsonData)
+	}
+}
+
+func TestModelCapabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    DMRModel
+		expected []string
+	}{
+		{"completion", DMRModel{Config: DMRConfig{Architecture: "llama"}}, []string{"completion"}},
+		{"unknown", DMRModel{}, []string{"completion"}},
+		{"vision", DMRModel{Config: DMRConfig{Architecture: "gemma3"}}, []string{"completion", "vision"}},
+		{"explicit", DMRModel{Capabilities: []string{"completion", "Tools"}}, []string{"completion", "tools"}},
+		{"embedding", DMRModel{Type: "embedding"}, []string{"embedding"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := modelCapabilities(tt.model)
+			if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertDMRToShowCapabilities(t *testing.T) {
+	conv := NewConverter()
+
+	vision := conv.ConvertDMRToShow(DMRModel{
+		ID:     "sha256:vision1",
+		Tags:   []string{"ai/gemma3:latest"},
+		Config: DMRConfig{Architecture: "gemma3"},
+	})
+	if strings.Join(vision.Capabilities, ",") != "completion,vision" {
+		t.Errorf("Expected completion and vision capabilities, got %v", vision.Capabilities)
+	}
+
+	plain := conv.ConvertDMRToShow(DMRModel{
+		ID:     "sha256:plain1",
+		Tags:   []string{"ai/smollm2:latest"},
+		Config: DMRConfig{Architecture: "llama"},
+	})
+	if strings.Join(plain.Capabilities, ",") != "completion" {
+		t.Errorf("Expected completion capability, got %v", plain.Capabilities)
+	}
+}
+
+func TestVisionFamilies(t *testing.T) {
+	tests := []struct {
+		architecture string
+		family       string
+	}{
+		{"llava", "llava"},
+		{"LLaVA-1.6", "llava"},
+		{"bakllava", "llava"},
+		{"moondream", "moondream"},
+	}
+
+	conv := NewConverter()
+	for _, tt := range tests {
+		t.Run(tt.architecture, func(t *testing.T) {
+			dmrModel := DMRModel{ID: "sha256:vision1", Tags: []string{"ai/" + tt.architecture}, Config: DMRConfig{Architecture: tt.architecture}}
+
+			show := conv.ConvertDMRToShow(dmrModel)
+			if show.Details.Family != tt.family {
+				t.Errorf("Expected family '%s', got '%s'", tt.family, show.Details.Family)
+			}
+
+			if strings.Join(show.Capabilities, ",") != "completion,vision" {
+				t.Errorf("Expected completion and vision capabilities, got %v", show.Capabilities)
+			}
+		})
+	}
+
+	// Vision families don't swallow llama
+	family, _ := determineFamily("llama3")
+	if family != "llama" {
+		t.Errorf("Expected llama3 to stay in the llama family, got '%s'", family)
+	}
+}
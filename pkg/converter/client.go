@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTPClientOptions configures the client built by NewHTTPClient
+type HTTPClientOptions struct {
+	// ConnectTimeout bounds establishing each connection, while Timeout
+	// bounds a whole request, including reading a slowly streamed body.
+	// Zero disables either limit.
+	ConnectTimeout time.Duration
+	Timeout        time.Duration
+
+	// HTTP2 also speaks HTTP/2 to http:// DMR URLs (h2c), falling back to
+	// HTTP/1.1 for servers that don't support it. https:// URLs always
+	// negotiate HTTP/2 with the server.
+	HTTP2 bool
+
+	// DisableRedirects stops the client from following 3xx responses, e.g.
+	// to an auth portal, so fetches fail with an HTTPStatusError carrying
+	// the redirect Location instead
+	DisableRedirects bool
+
+	// UnixSocket, when set, connects to this Unix socket for every request,
+	// e.g. DMR behind the Docker socket. URLs still choose the HTTP path and
+	// Host header, as in http://localhost/exp/vDD4.40/models.
+	UnixSocket string
+}
+
+// NewHTTPClient returns an HTTP client for DMR requests configured by options
+func NewHTTPClient(options HTTPClientOptions) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   options.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	if options.UnixSocket != "" {
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", options.UnixSocket)
+		}
+		// A proxy would be dialed over the socket instead of DMR
+		transport.Proxy = nil
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if options.HTTP2 {
+		// Without HTTP1, http:// requests use HTTP/2 with prior knowledge
+		h2c := transport.Clone()
+		h2c.Protocols = new(http.Protocols)
+		h2c.Protocols.SetUnencryptedHTTP2(true)
+		roundTripper = &h2cTransport{h2c: h2c, standard: transport}
+	}
+
+	client := &http.Client{
+		Transport: roundTripper,
+		Timeout:   options.Timeout,
+	}
+	if options.DisableRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
+
+// h2cTransport sends http:// requests over HTTP/2 with prior knowledge and
+// everything else through the standard transport. Hosts that fail to speak
+// h2c are retried and then always reached over HTTP/1.1.
+type h2cTransport struct {
+	h2c        *http.Transport
+	standard   *http.Transport
+	http1Hosts sync.Map
+}
+
+func (t *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" {
+		return t.standard.RoundTrip(req)
+	}
+	if _, ok := t.http1Hosts.Load(req.URL.Host); ok {
+		return t.standard.RoundTrip(req)
+	}
+
+	resp, err := t.h2c.RoundTrip(req)
+	if err == nil || !canFallBack(req, err) {
+		return resp, err
+	}
+
+	t.http1Hosts.Store(req.URL.Host, true)
+	return t.standard.RoundTrip(req)
+}
+
+func (t *h2cTransport) CloseIdleConnections() {
+	t.h2c.CloseIdleConnections()
+	t.standard.CloseIdleConnections()
+}
+
+// canFallBack reports whether a failed h2c request can be resent over
+// HTTP/1.1: it has no body to replay, wasn't canceled, and the server was
+// reached
+func canFallBack(req *http.Request, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody {
+		return false
+	}
+	if req.Context().Err() != nil {
+		return false
+	}
+	var opErr *net.OpError
+	return !errors.As(err, &opErr) || opErr.Op != "dial"
+}
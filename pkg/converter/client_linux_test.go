@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newStalledListenerAddr returns the address of a socket whose accept queue
+// is full, so new connections hang in the TCP handshake
+func newStalledListenerAddr(t *testing.T) string {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	t.Cleanup(func() { syscall.Close(fd) })
+
+	err = syscall.Bind(fd, &syscall.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}})
+	if err == nil {
+		err = syscall.Listen(fd, 0)
+	}
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	sockaddr, err := syscall.Getsockname(fd)
+	if err != nil {
+		t.Fatalf("Failed to get socket address: %v", err)
+	}
+	addr := fmt.Sprintf("127.0.0.1:%d", sockaddr.(*syscall.SockaddrInet4).Port)
+
+	// Fill the queue; the socket never accepts
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to fill accept queue: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return addr
+}
+
+func TestNewHTTPClientConnectTimeout(t *testing.T) {
+	addr := newStalledListenerAddr(t)
+
+	conv := NewConverterWithClient(NewHTTPClient(HTTPClientOptions{ConnectTimeout: 100 * time.Millisecond, Timeout: 10 * time.Second}))
+	start := time.Now()
+	_, err := conv.FetchDMRModels("http://" + addr + "/models")
+	elapsed := time.Since(start)
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("Expected connect timeout error, got %v", err)
+	}
+
+	if elapsed > 5*time.Second {
+		t.Errorf("Expected the connect timeout to fire well before the overall timeout, took %s", elapsed)
+	}
+}
@@ -0,0 +1,178 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClientSlowBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "sha256:test1",`))
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(` "tags": ["model1"]}]`))
+	}))
+	defer server.Close()
+
+	// A short connect timeout doesn't cut off a body that streams slowly
+	conv := NewConverterWithClient(NewHTTPClient(HTTPClientOptions{ConnectTimeout: 50 * time.Millisecond, Timeout: 5 * time.Second}))
+	models, err := conv.FetchDMRModels(server.URL)
+	if err != nil {
+		t.Fatalf("Expected slow body within the overall timeout, got %v", err)
+	}
+	if len(models) != 1 {
+		t.Errorf("Expected 1 model, got %d", len(models))
+	}
+
+	// The overall timeout still bounds the whole request
+	conv = NewConverterWithClient(NewHTTPClient(HTTPClientOptions{ConnectTimeout: 5 * time.Second, Timeout: 50 * time.Millisecond}))
+	_, err = conv.FetchDMRModels(server.URL)
+	if !errors.Is(err, ErrFetch) {
+		t.Errorf("Expected fetch error from the overall timeout, got %v", err)
+	}
+}
+
+// protoServer responds with the protocol the request arrived over
+func protoServer(protocols func(*http.Protocols)) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"id": "sha256:test1", "tags": [%q]}]`, r.Proto)
+	}))
+	if protocols != nil {
+		server.Config.Protocols = new(http.Protocols)
+		protocols(server.Config.Protocols)
+	}
+	return server
+}
+
+// fetchProto returns the protocol the server saw for a fetch through client
+func fetchProto(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	models, err := NewConverterWithClient(client).FetchDMRModels(url)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return models[0].Tags[0]
+}
+
+func TestNewHTTPClientHTTP2(t *testing.T) {
+	t.Run("tls negotiates h2", func(t *testing.T) {
+		server := protoServer(nil)
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		defer server.Close()
+
+		client := NewHTTPClient(HTTPClientOptions{})
+		client.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+		if proto := fetchProto(t, client, server.URL); proto != "HTTP/2.0" {
+			t.Errorf("Expected HTTP/2.0, got %s", proto)
+		}
+	})
+
+	t.Run("tls falls back to http/1.1", func(t *testing.T) {
+		server := protoServer(nil)
+		server.StartTLS()
+		defer server.Close()
+
+		client := NewHTTPClient(HTTPClientOptions{})
+		client.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+		if proto := fetchProto(t, client, server.URL); proto != "HTTP/1.1" {
+			t.Errorf("Expected HTTP/1.1, got %s", proto)
+		}
+	})
+
+	t.Run("h2c", func(t *testing.T) {
+		server := protoServer(func(p *http.Protocols) {
+			p.SetHTTP1(true)
+			p.SetUnencryptedHTTP2(true)
+		})
+		server.Start()
+		defer server.Close()
+
+		if proto := fetchProto(t, NewHTTPClient(HTTPClientOptions{}), server.URL); proto != "HTTP/1.1" {
+			t.Errorf("Expected HTTP/1.1 without the HTTP2 option, got %s", proto)
+		}
+
+		if proto := fetchProto(t, NewHTTPClient(HTTPClientOptions{HTTP2: true}), server.URL); proto != "HTTP/2.0" {
+			t.Errorf("Expected HTTP/2.0, got %s", proto)
+		}
+	})
+
+	t.Run("h2c falls back to http/1.1", func(t *testing.T) {
+		server := protoServer(nil)
+		server.Start()
+		defer server.Close()
+
+		client := NewHTTPClient(HTTPClientOptions{HTTP2: true})
+		for i := 0; i < 2; i++ {
+			if proto := fetchProto(t, client, server.URL); proto != "HTTP/1.1" {
+				t.Errorf("Expected HTTP/1.1 fallback, got %s", proto)
+			}
+		}
+	})
+}
+
+func TestNewHTTPClientRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/models" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+	}))
+	defer server.Close()
+
+	// Redirects are followed by default
+	models, err := NewConverterWithClient(NewHTTPClient(HTTPClientOptions{})).FetchDMRModels(server.URL + "/models")
+	if err != nil || len(models) != 1 {
+		t.Fatalf("Expected redirect to be followed, got %d models and error %v", len(models), err)
+	}
+
+	_, err = NewConverterWithClient(NewHTTPClient(HTTPClientOptions{DisableRedirects: true})).FetchDMRModels(server.URL + "/models")
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected HTTPStatusError for unfollowed redirect, got %v", err)
+	}
+
+	if statusErr.StatusCode != http.StatusFound || statusErr.Location != "/login" {
+		t.Errorf("Expected 302 to /login, got %d to '%s'", statusErr.StatusCode, statusErr.Location)
+	}
+
+	if !strings.Contains(err.Error(), "redirect to /login") {
+		t.Errorf("Expected Location in error message, got %q", err.Error())
+	}
+}
+
+func TestNewHTTPClientUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "dmr.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("Unix sockets unavailable: %v", err)
+	}
+
+	var host string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	for _, http2 := range []bool{false, true} {
+		conv := NewConverterWithClient(NewHTTPClient(HTTPClientOptions{UnixSocket: socket, HTTP2: http2}))
+		models, err := conv.FetchDMRModels("http://localhost/exp/vDD4.40/models")
+		if err != nil || len(models) != 1 {
+			t.Fatalf("HTTP2 %v: expected 1 model over the socket, got %d and error %v", http2, len(models), err)
+		}
+		if host != "localhost" {
+			t.Errorf("HTTP2 %v: expected Host 'localhost', got '%s'", http2, host)
+		}
+	}
+}
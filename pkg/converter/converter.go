@@ -1,21 +1,85 @@
 package converter
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// DefaultConcurrency is the number of DMR servers fetched in parallel by
+// ConvertFromURLs when Converter.Concurrency is not set
+const DefaultConcurrency = 4
+
 // DMR API response structures
 type DMRModel struct {
-	ID      string    `json:"id"`
-	Tags    []string  `json:"tags"`
-	Created int64     `json:"created"`
-	Config  DMRConfig `json:"config"`
+	ID      string     `json:"id"`
+	Tags    []string   `json:"tags"`
+	Created int64      `json:"created"`
+	Config  DMRConfig  `json:"config"`
+	Layers  []DMRLayer `json:"layers,omitempty"`
+	Digests []string   `json:"digests,omitempty"`
+
+	Description string `json:"description,omitempty"`
+	License     string `json:"license,omitempty"`
+
+	// Type and Capabilities are reported by some DMR versions, e.g. "embedding"
+	Type         string   `json:"type,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// UnmarshalJSON accepts created as either Unix seconds or an RFC3339
+// timestamp, as different DMR versions report it, and stores Unix seconds
+func (m *DMRModel) UnmarshalJSON(data []byte) error {
+	type plainModel DMRModel
+	var raw struct {
+		*plainModel
+		Created json.RawMessage `json:"created"`
+	}
+	raw.plainModel = (*plainModel)(m)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	m.Created = 0
+	if len(raw.Created) == 0 || string(raw.Created) == "null" {
+		return nil
+	}
+	if raw.Created[0] != '"' {
+		return json.Unmarshal(raw.Created, &m.Created)
+	}
+
+	var timestamp string
+	if err := json.Unmarshal(raw.Created, &timestamp); err != nil {
+		return err
+	}
+	// RFC3339Nano also parses timestamps without fractional seconds
+	created, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return fmt.Errorf("invalid created timestamp %q: %w", timestamp, err)
+	}
+	m.Created = created.Unix()
+	return nil
+}
+
+// DMRLayer is a per-layer entry reported by newer DMR versions
+type DMRLayer struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType,omitempty"`
+	Size      int64  `json:"size,omitempty"`
 }
 
 type DMRConfig struct {
@@ -24,6 +88,81 @@ type DMRConfig struct {
 	Parameters   string `json:"parameters"`
 	Architecture string `json:"architecture"`
 	Size         string `json:"size"`
+	ContextSize  int64  `json:"context_size,omitempty"`
+
+	// Extra holds config fields this package doesn't model yet, keyed by
+	// their JSON name
+	Extra map[string]any `json:"-"`
+}
+
+// knownConfigFields are the JSON names of the modeled DMRConfig fields
+var knownConfigFields = map[string]bool{
+	"format":       true,
+	"quantization": true,
+	"parameters":   true,
+	"architecture": true,
+	"size":         true,
+	"context_size": true,
+}
+
+// UnmarshalJSON accepts size as either a string like "1 GiB" or a number of
+// bytes, which is kept as its decimal string, and collects unknown fields
+// into Extra
+func (c *DMRConfig) UnmarshalJSON(data []byte) error {
+	type plainConfig DMRConfig
+	var raw struct {
+		*plainConfig
+		Size json.RawMessage `json:"size"`
+	}
+	raw.plainConfig = (*plainConfig)(c)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if err := c.unmarshalExtra(data); err != nil {
+		return err
+	}
+
+	c.Size = ""
+	if len(raw.Size) == 0 || string(raw.Size) == "null" {
+		return nil
+	}
+	if raw.Size[0] == '"' {
+		return json.Unmarshal(raw.Size, &c.Size)
+	}
+
+	var size json.Number
+	if err := json.Unmarshal(raw.Size, &size); err != nil {
+		return fmt.Errorf("config size must be a string or a number: %w", err)
+	}
+	c.Size = size.String()
+	return nil
+}
+
+// unmarshalExtra sets Extra to the fields of a config object that aren't
+// modeled. Like encoding/json, known names are matched case-insensitively.
+func (c *DMRConfig) unmarshalExtra(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	c.Extra = nil
+	for key, value := range fields {
+		if knownConfigFields[strings.ToLower(key)] {
+			continue
+		}
+
+		var decoded any
+		if err := json.Unmarshal(value, &decoded); err != nil {
+			return err
+		}
+		if c.Extra == nil {
+			c.Extra = make(map[string]any)
+		}
+		c.Extra[key] = decoded
+	}
+	return nil
 }
 
 // Ollama API response structures
@@ -38,6 +177,18 @@ type OllamaModel struct {
 	Size       int64         `json:"size"`
 	Digest     string        `json:"digest"`
 	Details    OllamaDetails `json:"details"`
+	Tags       []string      `json:"tags,omitempty"`
+
+	Description string `json:"description,omitempty"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+
+	// Extra carries unmodeled DMR config fields, set only when
+	// Converter.PreserveUnknownFields is enabled
+	Extra map[string]any `json:"extra,omitempty"`
+
+	// SizeString is the raw DMR size that Size was parsed from, set only
+	// when Converter.IncludeSizeString is enabled
+	SizeString string `json:"size_string,omitempty"`
 }
 
 type OllamaDetails struct {
@@ -52,6 +203,151 @@ type OllamaDetails struct {
 // Converter provides methods to convert DMR models to Ollama format
 type Converter struct {
 	client *http.Client
+
+	// Concurrency limits how many DMR servers ConvertFromURLs fetches at once
+	Concurrency int
+
+	// ServerTimeout bounds each fetch in ConvertFromURLs. Servers that take
+	// longer are skipped with a warning instead of failing the whole result,
+	// unless no server finished in time, which is an ErrFetch error.
+	ServerTimeout time.Duration
+
+	// Progress, when set, is called by FetchDMRModelsFromURLs each time a
+	// server's fetch completes, successfully or not, with the number of
+	// servers done so far and the total
+	Progress func(done, total int)
+
+	// Logger receives warnings about skipped servers and models. Nothing is
+	// logged when it is nil.
+	Logger *slog.Logger
+
+	// FamilyResolver, when set, overrides the built-in family detection.
+	// Returning "" falls back to the built-in mapping.
+	FamilyResolver func(architecture, parameters, quantization string) string
+
+	// FamilyMap maps lowercase architectures to families, overriding the
+	// built-in mapping. FamilyResolver, when set, is consulted first.
+	FamilyMap map[string]string
+
+	// DefaultFamily, when set, replaces the family of models whose
+	// architecture isn't recognized, instead of passing it through
+	DefaultFamily string
+
+	// NormalizeParameterSizes rewrites parameter sizes with
+	// NormalizeParameterSize, e.g. "1500 M" becomes "1.5B"
+	NormalizeParameterSizes bool
+
+	// NormalizeQuantizations rewrites quantization levels with
+	// NormalizeQuantization, e.g. "q4_k_m" becomes "Q4_K_M"
+	NormalizeQuantizations bool
+
+	// PreserveDigestCase keeps digests as DMR reported them. By default they
+	// are lowercased, since Ollama clients compare digests case-sensitively.
+	PreserveDigestCase bool
+
+	// SynthesizeLatest adds a "<name>:latest" alias of the first variant of
+	// each model that has no :latest tag, e.g. for clients asking for
+	// "llama3:latest" when DMR only has "llama3:8b"
+	SynthesizeLatest bool
+
+	// StrictSize handles sizes that can't be parsed, which are otherwise
+	// reported as 0: StrictSizeFail aborts the conversion with an ErrParse
+	// error and StrictSizeSkip drops the model with a warning
+	StrictSize string
+
+	// StrictDuplicates fails the conversion with an ErrDuplicateName error
+	// when models with different digests convert to the same name, instead
+	// of only logging a warning
+	StrictDuplicates bool
+
+	// SkipInvalid drops models that have neither tags nor an ID, which
+	// would otherwise be converted with an empty name
+	SkipInvalid bool
+
+	// PreserveUnknownFields copies DMR config fields this package doesn't
+	// model into each model's Extra
+	PreserveUnknownFields bool
+
+	// IncludeSizeString keeps the raw DMR size in each model's SizeString,
+	// for diagnosing size parsing
+	IncludeSizeString bool
+
+	// ModelTransform, when set, post-processes each converted model.
+	// Returning a model with an empty Name drops it from the output.
+	ModelTransform func(OllamaModel) OllamaModel
+
+	// NameTemplate, when set, renames each model after ModelTransform to
+	// the template executed with its NameTemplateData, see
+	// ParseNameTemplate. An empty result drops the model, and a template
+	// that fails on a model aborts the conversion with the error.
+	NameTemplate *template.Template
+
+	// FieldPaths maps DMRConfig fields (by JSON name, e.g. "parameters") to
+	// JSONPath-like locations in each DMR model (e.g. "$.descriptor.params"),
+	// for DMR forks with a different schema. Fields without a path, or whose
+	// path doesn't resolve, keep the standard "$.config.<field>" value.
+	FieldPaths map[string]string
+
+	// MaxModels caps the number of converted models, keeping the first ones.
+	// Zero means no limit.
+	MaxModels int
+
+	// Since, when set, drops models created before it, see FilterSince
+	Since time.Time
+
+	// APIVersion selects the DMR response shape to accept: DMRAPIV0 for a
+	// bare array, DMRAPIV1 for a {"models": [...]} object. Empty or
+	// DMRAPIAuto detects the shape of each response.
+	APIVersion string
+
+	// ModelPath is the path of DMR's single-model endpoint used by
+	// FetchDMRModel, appended to the models URL with "{name}" replaced by
+	// the model name. Empty means DefaultModelPath.
+	ModelPath string
+
+	// MaxPages caps how many pages of a paginated DMR catalog are followed
+	// per fetch. Zero means DefaultMaxPages.
+	MaxPages int
+
+	// KeepAlive, when positive, sets each model's ExpiresAt to the time of
+	// conversion plus KeepAlive
+	KeepAlive time.Duration
+
+	// Templates maps a model family to the prompt TEMPLATE used in the
+	// synthesized /api/show Modelfile. Families without an entry use
+	// DefaultTemplate.
+	Templates map[string]string
+
+	// ConditionalRequests enables ETag/Last-Modified revalidation of DMR
+	// fetches. A 304 Not Modified reuses the models from the previous fetch.
+	ConditionalRequests bool
+
+	// Headers are added to every DMR request. The request ID and AuthToken
+	// take precedence over Headers with the same name.
+	Headers http.Header
+
+	// AuthToken, when set, is sent as a bearer token on every DMR request
+	AuthToken string
+
+	// RecordResponse, when set, is called with the URL and raw body of every
+	// 200 response to a DMR model list request, including each page, before
+	// the body is parsed. Calls for different servers may be concurrent. It
+	// isn't called when a conditional request reuses cached models.
+	RecordResponse func(url string, body []byte)
+
+	// Tracer, when set, records a span for each DMR fetch and conversion,
+	// as children of any span in the context passed in
+	Tracer trace.Tracer
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedFetch
+}
+
+// cachedFetch holds the validators and models from a previous DMR fetch
+type cachedFetch struct {
+	etag         string
+	lastModified string
+	models       []DMRModel
 }
 
 // NewConverter creates a new Converter instance
@@ -70,142 +366,934 @@ func NewConverterWithClient(client *http.Client) *Converter {
 	}
 }
 
+// Close releases idle connections held by the converter's HTTP transport,
+// if the transport supports it. It is safe to call on converters created
+// with NewConverter and on custom clients, and always returns nil.
+func (c *Converter) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
 // FetchDMRModels fetches models from the DMR API
 func (c *Converter) FetchDMRModels(url string) ([]DMRModel, error) {
-	resp, err := c.client.Get(url)
+	return c.FetchDMRModelsContext(context.Background(), url)
+}
+
+// FetchDMRModelsContext fetches models from the DMR API, aborting when ctx is done.
+// Paginated catalogs are followed up to MaxPages and returned as one list.
+func (c *Converter) FetchDMRModelsContext(ctx context.Context, url string) ([]DMRModel, error) {
+	ctx, span := c.startSpan(ctx, "dmr.fetch", attribute.String("url.full", url))
+	dmrModels, err := c.fetchDMRModels(ctx, url)
+	span.SetAttributes(attribute.Int("dmr.models", len(dmrModels)))
+	endSpan(span, err)
+	return dmrModels, err
+}
+
+// fetchDMRModels does the work of FetchDMRModelsContext, outside its span
+func (c *Converter) fetchDMRModels(ctx context.Context, url string) ([]DMRModel, error) {
+	req, err := c.newDMRRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, hasCache := c.cachedFetch(url)
+	if hasCache {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch from DMR API: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrFetch, err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && hasCache {
+		return cached.models, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("DMR API returned status: %d", resp.StatusCode)
+		return nil, newHTTPStatusError(resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("%w: failed to read response body: %w", ErrFetch, err)
+	}
+	c.recordResponse(url, body)
+
+	dmrModels, next, err := c.parseDMRPage(url, resp.Header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if next != "" {
+		rest, err := c.fetchRemainingPages(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		dmrModels = append(dmrModels, rest...)
+	}
+
+	c.storeFetch(url, resp.Header, dmrModels)
+
+	return dmrModels, nil
+}
+
+// newDMRRequest creates a GET request to DMR carrying the configured
+// headers, the request ID from ctx and the configured credentials
+func (c *Converter) newDMRRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DMR request: %w", err)
 	}
+	for key, values := range c.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	setRequestID(req)
+	if c.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	}
+	return req, nil
+}
 
+// recordResponse passes a DMR response body to RecordResponse, if set
+func (c *Converter) recordResponse(url string, body []byte) {
+	if c.RecordResponse != nil {
+		c.RecordResponse(url, body)
+	}
+}
+
+// logger returns the configured logger, or one that discards everything
+func (c *Converter) logger() *slog.Logger {
+	if c.Logger == nil {
+		return discardLogger
+	}
+	return c.Logger
+}
+
+// discardLogger is shared by every converter without a Logger, as logger
+// runs for each converted model
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// ParseDMRModels decodes a DMR response body, a v0 model array or a v1
+// {"models": [...]} object checked against APIVersion, applying any custom
+// field paths. Pagination links in a v1 object are ignored.
+func (c *Converter) ParseDMRModels(data []byte) ([]DMRModel, error) {
+	dmrModels, _, err := c.parseDMRBody(data)
+	return dmrModels, err
+}
+
+// parseDMRModelArray decodes a bare array of DMR models, applying any
+// custom field paths
+func (c *Converter) parseDMRModelArray(data []byte) ([]DMRModel, error) {
 	var dmrModels []DMRModel
-	err = json.Unmarshal(body, &dmrModels)
+	err := json.Unmarshal(data, &dmrModels)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse DMR JSON: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+
+	if len(c.FieldPaths) == 0 {
+		return dmrModels, nil
+	}
+
+	var rawModels []map[string]any
+	err = json.Unmarshal(data, &rawModels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+
+	for i := range dmrModels {
+		err = applyFieldPaths(&dmrModels[i].Config, rawModels[i], c.FieldPaths)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrParse, err)
+		}
 	}
 
 	return dmrModels, nil
 }
 
-// ConvertDMRToOllama converts DMR models to Ollama format
+// cachedFetch returns the previous fetch of url when conditional requests are enabled
+func (c *Converter) cachedFetch(url string) (cachedFetch, bool) {
+	if !c.ConditionalRequests {
+		return cachedFetch{}, false
+	}
+
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	cached, ok := c.cache[url]
+	return cached, ok
+}
+
+// storeFetch remembers a fetch of url if the server sent cache validators
+func (c *Converter) storeFetch(url string, header http.Header, models []DMRModel) {
+	if !c.ConditionalRequests {
+		return
+	}
+
+	etag := header.Get("ETag")
+	lastModified := header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return
+	}
+
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	if c.cache == nil {
+		c.cache = make(map[string]cachedFetch)
+	}
+	c.cache[url] = cachedFetch{etag: etag, lastModified: lastModified, models: models}
+}
+
+// ConvertDMRToOllama converts DMR models to Ollama format, keeping their
+// order so the same input always produces the same output. It never fails:
+// a size StrictSizeFail rejects is reported as 0, and a name
+// StrictDuplicates or NameTemplate rejects is kept, each with a warning.
+// Use ConvertDMRToOllamaContext to get those errors instead.
 func (c *Converter) ConvertDMRToOllama(dmrModels []DMRModel) OllamaResponse {
-	var ollamaModels []OllamaModel
+	// The background context is never cancelled, and lenient conversion logs every other error
+	response, _ := c.convertDMRToOllama(context.Background(), dmrModels, true)
+	return response
+}
+
+// ConvertDMRToOllamaContext converts DMR models, stopping with ctx's error
+// when it is done, with an ErrParse error for a size StrictSizeFail rejects,
+// with an ErrDuplicateName error under StrictDuplicates, or with the error
+// of a NameTemplate that fails on a model
+func (c *Converter) ConvertDMRToOllamaContext(ctx context.Context, dmrModels []DMRModel) (OllamaResponse, error) {
+	return c.convertDMRToOllama(ctx, dmrModels, false)
+}
+
+// convertDMRToOllama converts DMR models in a span. When lenient, errors
+// other than ctx's are logged and the conversion carries on.
+func (c *Converter) convertDMRToOllama(ctx context.Context, dmrModels []DMRModel, lenient bool) (OllamaResponse, error) {
+	ctx, span := c.startSpan(ctx, "dmr.convert", attribute.Int("dmr.models", len(dmrModels)))
+	response, err := c.convertModels(ctx, dmrModels, lenient)
+	span.SetAttributes(attribute.Int("ollama.models", len(response.Models)))
+	endSpan(span, err)
+	return response, err
+}
+
+// convertModels does the work of convertDMRToOllama, outside its span
+func (c *Converter) convertModels(ctx context.Context, dmrModels []DMRModel, lenient bool) (OllamaResponse, error) {
+	ollamaModels := make([]OllamaModel, 0, len(dmrModels))
+	err := c.eachModel(ctx, dmrModels, lenient, func(model OllamaModel) error {
+		ollamaModels = append(ollamaModels, model)
+		return nil
+	})
+	if err != nil {
+		return OllamaResponse{}, err
+	}
+	return OllamaResponse{Models: ollamaModels}, nil
+}
+
+// eachModel converts dmrModels and passes each converted model, followed
+// by any synthesized :latest aliases, to emit in order. It is the
+// conversion shared by the buffered and streamed output, stopping once
+// MaxModels models were emitted. When lenient, errors other than ctx's and
+// emit's are logged and the conversion carries on.
+func (c *Converter) eachModel(ctx context.Context, dmrModels []DMRModel, lenient bool, emit func(OllamaModel) error) error {
+	dmrModels = FilterSince(dmrModels, c.Since)
+
+	// Large lists often share creation times, so format each one only once per call
+	timestamps := make(map[int64]string)
+	aliases := newLatestAliases()
+	names := make(duplicateNames)
+	truncated := false
+	logger := c.contextLogger(ctx)
+	emitted := 0
 
 	for _, dmrModel := range dmrModels {
-		ollamaModel := c.convertSingleModel(dmrModel)
-		ollamaModels = append(ollamaModels, ollamaModel)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if c.atMaxModels(emitted) {
+			c.warnTruncated(len(dmrModels))
+			truncated = true
+			break
+		}
+
+		if c.skipInvalid(dmrModel) {
+			continue
+		}
+
+		skip, err := c.checkSize(dmrModel)
+		if err := lenientError(err, lenient, logger); err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+
+		warnMissingConfig(dmrModel, logger)
+		model, ok, err := c.transformModel(c.convertModel(dmrModel, timestamps))
+		if err := lenientError(err, lenient, logger); err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		err = c.checkDuplicate(names, model, logger)
+		if err := lenientError(err, lenient, logger); err != nil {
+			return err
+		}
+
+		if err := emit(model); err != nil {
+			return err
+		}
+		emitted++
+		aliases.add(model)
+	}
+
+	if c.SynthesizeLatest {
+		// Synthesized aliases count against MaxModels like any other model
+		for _, alias := range aliases.models() {
+			if c.atMaxModels(emitted) {
+				if !truncated {
+					c.warnTruncated(len(dmrModels))
+				}
+				break
+			}
+			if err := emit(alias); err != nil {
+				return err
+			}
+			emitted++
+		}
+	}
+	return nil
+}
+
+// transformModel applies ModelTransform and NameTemplate, reporting false
+// if the model was dropped. A model NameTemplate fails on keeps its name.
+func (c *Converter) transformModel(model OllamaModel) (OllamaModel, bool, error) {
+	if c.ModelTransform != nil {
+		model = c.ModelTransform(model)
+		if model.Name == "" {
+			return model, false, nil
+		}
+	}
+
+	if c.NameTemplate == nil {
+		return model, true, nil
+	}
+
+	name, err := executeNameTemplate(c.NameTemplate, model)
+	if err != nil {
+		return model, true, err
+	}
+	model.Name = name
+	model.Model = name
+	return model, name != "", nil
+}
+
+// skipInvalid reports whether SkipInvalid drops dmrModel for having
+// neither tags nor an ID to name it by, logging the skip
+func (c *Converter) skipInvalid(dmrModel DMRModel) bool {
+	if !c.SkipInvalid || len(dmrModel.Tags) > 0 || strings.TrimPrefix(dmrModel.ID, "sha256:") != "" {
+		return false
+	}
+
+	c.logger().Warn("skipping DMR model without tags or ID")
+	return true
+}
+
+// Values for Converter.StrictSize
+const (
+	StrictSizeFail = "fail"
+	StrictSizeSkip = "skip"
+)
+
+// checkSize applies StrictSize to a model whose size can't be parsed,
+// reporting whether to skip the model or the error to abort with
+func (c *Converter) checkSize(dmrModel DMRModel) (bool, error) {
+	size := dmrModel.Config.Size
+	if c.StrictSize == "" || strings.TrimSpace(size) == "" {
+		return false, nil
+	}
+	if _, ok := parseSize(size); ok {
+		return false, nil
+	}
+
+	if c.StrictSize == StrictSizeSkip {
+		c.logger().Warn("skipping DMR model with unparseable size", "id", dmrModel.ID, "size", size)
+		return true, nil
+	}
+	return false, fmt.Errorf("%w: model %s has unparseable size %q", ErrParse, dmrModel.ID, size)
+}
+
+// lenientError returns err, or logs it and returns nil when lenient, for
+// conversions that keep the model instead of failing
+func lenientError(err error, lenient bool, logger *slog.Logger) error {
+	if err == nil || !lenient {
+		return err
 	}
+	logger.Warn("converting DMR model despite error", "error", err)
+	return nil
+}
+
+// atMaxModels reports whether count converted models already fill MaxModels
+func (c *Converter) atMaxModels(count int) bool {
+	return c.MaxModels > 0 && count >= c.MaxModels
+}
 
-	return OllamaResponse{Models: ollamaModels}
+// warnTruncated logs that the output was capped at MaxModels
+func (c *Converter) warnTruncated(total int) {
+	c.logger().Warn("truncating converted models", "max_models", c.MaxModels, "dmr_models", total)
 }
 
 // ConvertFromURL fetches DMR models from a URL and converts them to Ollama format
 func (c *Converter) ConvertFromURL(url string) (OllamaResponse, error) {
-	dmrModels, err := c.FetchDMRModels(url)
+	return c.ConvertFrom(context.Background(), c.HTTPFetcher(url))
+}
+
+// ConvertFromURLs fetches DMR models from several servers concurrently and
+// converts them to a single Ollama response. Models are kept in the order of
+// urls. Servers that fail are left out and their errors are joined into the
+// returned error, so callers can still use the models that were fetched.
+func (c *Converter) ConvertFromURLs(ctx context.Context, urls []string) (OllamaResponse, error) {
+	dmrModels, err := c.FetchDMRModelsFromURLs(ctx, urls)
+	if err != nil && ctx.Err() != nil {
+		return OllamaResponse{}, err
+	}
+
+	response, convertErr := c.ConvertDMRToOllamaContext(ctx, dmrModels)
+	if convertErr != nil {
+		return OllamaResponse{}, convertErr
+	}
+	return response, err
+}
+
+// FetchDMRModelsFromURLs fetches DMR models from several servers concurrently,
+// as described for ConvertFromURLs, without converting them
+func (c *Converter) FetchDMRModelsFromURLs(ctx context.Context, urls []string) ([]DMRModel, error) {
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([][]DMRModel, len(urls))
+	errs := make([]error, len(urls))
+	timedOut := make([]bool, len(urls))
+
+	// Report progress in completion order, one call at a time
+	var progressMu sync.Mutex
+	fetched := 0
+	reportFetched := func() {
+		if c.Progress == nil {
+			return
+		}
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		fetched++
+		c.Progress(fetched, len(urls))
+	}
+
+	// Bound the number of in-flight fetches with a semaphore
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("%s: %w", url, ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+			defer reportFetched()
+
+			fetchCtx := ctx
+			if c.ServerTimeout > 0 {
+				var cancel context.CancelFunc
+				fetchCtx, cancel = context.WithTimeout(ctx, c.ServerTimeout)
+				defer cancel()
+			}
+
+			models, err := c.FetchDMRModelsContext(fetchCtx, url)
+			if err != nil {
+				// A server exceeding its own timeout is skipped rather than failing the result
+				if ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+					c.contextLogger(ctx).Warn("skipping DMR server that exceeded its timeout", "url", url, "timeout", c.ServerTimeout)
+					timedOut[i] = true
+					return
+				}
+				errs[i] = fmt.Errorf("%s: %w", url, err)
+				return
+			}
+			results[i] = models
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(urls) > 0 && !slices.Contains(timedOut, false) {
+		return nil, fmt.Errorf("%w: no DMR server responded within %s: %w", ErrFetch, c.ServerTimeout, context.DeadlineExceeded)
+	}
+
+	var dmrModels []DMRModel
+	for _, models := range results {
+		dmrModels = append(dmrModels, models...)
+	}
+
+	return dmrModels, errors.Join(errs...)
+}
+
+// ConvertFromJSONContext converts DMR models from JSON to Ollama format like
+// ConvertFromJSON, but aborts with ctx's error if ctx is done before the
+// conversion finishes. Useful for very large captured model lists.
+func (c *Converter) ConvertFromJSONContext(ctx context.Context, jsonData []byte) (OllamaResponse, error) {
+	dmrModels, err := c.ParseDMRModels(jsonData)
 	if err != nil {
 		return OllamaResponse{}, err
 	}
 
-	return c.ConvertDMRToOllama(dmrModels), nil
+	return c.ConvertDMRToOllamaContext(ctx, dmrModels)
 }
 
 // ConvertFromJSON converts DMR models from JSON string to Ollama format
 func (c *Converter) ConvertFromJSON(jsonData []byte) (OllamaResponse, error) {
-	var dmrModels []DMRModel
-	err := json.Unmarshal(jsonData, &dmrModels)
+	dmrModels, err := c.ParseDMRModels(jsonData)
 	if err != nil {
-		return OllamaResponse{}, fmt.Errorf("failed to parse DMR JSON: %w", err)
+		return OllamaResponse{}, err
 	}
 
-	return c.ConvertDMRToOllama(dmrModels), nil
+	return c.ConvertDMRToOllamaContext(context.Background(), dmrModels)
+}
+
+// ConvertOllamaToDMR converts Ollama models back to DMR format. The mapping
+// is lossy: the architecture is set to the Ollama family (so "phi4" comes
+// back as "phi3"), the size is re-encoded with two decimals, the creation
+// time is truncated to seconds, and tags only include names Ollama kept.
+func (c *Converter) ConvertOllamaToDMR(response OllamaResponse) []DMRModel {
+	dmrModels := make([]DMRModel, 0, len(response.Models))
+
+	for _, model := range response.Models {
+		var created int64
+		if modifiedAt, err := time.Parse(time.RFC3339, model.ModifiedAt); err == nil {
+			created = modifiedAt.Unix()
+		}
+
+		tags := []string{model.Name}
+		if model.Model != "" && model.Model != model.Name {
+			tags = append(tags, model.Model)
+		}
+		tags = append(tags, model.Tags...)
+
+		dmrModels = append(dmrModels, DMRModel{
+			ID:      "sha256:" + model.Digest,
+			Tags:    tags,
+			Created: created,
+			Config: DMRConfig{
+				Format:       model.Details.Format,
+				Quantization: model.Details.QuantizationLevel,
+				Parameters:   model.Details.ParameterSize,
+				Architecture: model.Details.Family,
+				Size:         FormatSize(model.Size),
+			},
+		})
+	}
+
+	return dmrModels
 }
 
 // convertSingleModel converts a single DMR model to Ollama format
 func (c *Converter) convertSingleModel(dmrModel DMRModel) OllamaModel {
+	return c.convertModel(dmrModel, nil)
+}
+
+// convertModel converts a single DMR model, reusing timestamps already
+// formatted in timestamps when it is non-nil
+func (c *Converter) convertModel(dmrModel DMRModel, timestamps map[int64]string) OllamaModel {
 	// Convert timestamp from Unix timestamp to RFC3339 format
-	modifiedAt := time.Unix(dmrModel.Created, 0).Format(time.RFC3339)
+	modifiedAt := formatCreated(dmrModel.Created, timestamps)
+
+	config := c.configWithDefaults(dmrModel)
 
 	// Convert size string to bytes (approximate)
-	sizeBytes := parseSizeString(dmrModel.Config.Size)
+	sizeBytes := parseSizeString(config.Size)
 
 	// Extract digest from ID (remove "sha256:" prefix)
 	digest := strings.TrimPrefix(dmrModel.ID, "sha256:")
+	modelDigest := digest
+	if layerDigest := primaryLayerDigest(dmrModel); layerDigest != "" {
+		modelDigest = strings.TrimPrefix(layerDigest, "sha256:")
+	}
+	if !c.PreserveDigestCase {
+		modelDigest = strings.ToLower(modelDigest)
+	}
+
+	parameterSize := config.Parameters
+	if c.NormalizeParameterSizes {
+		parameterSize = NormalizeParameterSize(parameterSize)
+	}
+	quantization := config.Quantization
+	if c.NormalizeQuantizations {
+		quantization = NormalizeQuantization(quantization)
+	}
 
-	// Determine family from architecture
-	family := determineFamily(dmrModel.Config.Architecture)
+	// Determine family from architecture, or the name when it is blank
+	family := c.resolveFamily(config, dmrModel.Tags)
 
-	// Get model name from first tag, or use digest as fallback
-	modelName := digest
+	// Name is the alias clients ask for and Model the canonical tag it
+	// resolves to, with the digest as fallback when there are no tags
+	modelName, canonicalName := digest, digest
 	if len(dmrModel.Tags) > 0 {
-		modelName = dmrModel.Tags[0]
+		modelName, canonicalName = splitTags(dmrModel.Tags)
 	}
 
 	return OllamaModel{
 		Name:       modelName,
-		Model:      modelName,
+		Model:      canonicalName,
 		ModifiedAt: modifiedAt,
 		Size:       sizeBytes,
-		Digest:     digest,
+		Digest:     modelDigest,
 		Details: OllamaDetails{
 			ParentModel:       "",
-			Format:            dmrModel.Config.Format,
+			Format:            config.Format,
 			Family:            family,
 			Families:          []string{family},
-			ParameterSize:     dmrModel.Config.Parameters,
-			QuantizationLevel: dmrModel.Config.Quantization,
+			ParameterSize:     parameterSize,
+			QuantizationLevel: quantization,
 		},
+		Description: dmrModel.Description,
+		ExpiresAt:   c.expiresAt(),
+		SizeString:  c.sizeString(config.Size),
+		Extra:       c.extraFields(config),
+	}
+}
+
+// extraFields returns the unmodeled config fields when PreserveUnknownFields is enabled
+func (c *Converter) extraFields(config DMRConfig) map[string]any {
+	if !c.PreserveUnknownFields {
+		return nil
+	}
+	return config.Extra
+}
+
+// sizeString returns the raw DMR size when IncludeSizeString is enabled
+func (c *Converter) sizeString(size string) string {
+	if !c.IncludeSizeString {
+		return ""
+	}
+	return size
+}
+
+// configWithDefaults returns the model's config, filling in defaults when
+// DMR omitted the config block entirely
+func (c *Converter) configWithDefaults(dmrModel DMRModel) DMRConfig {
+	if !hasConfig(dmrModel) {
+		return DMRConfig{Format: "gguf"}
+	}
+	return dmrModel.Config
+}
+
+// hasConfig reports whether DMR sent a config block for dmrModel
+func hasConfig(dmrModel DMRModel) bool {
+	return !reflect.ValueOf(dmrModel.Config).IsZero()
+}
+
+// warnMissingConfig logs a model converted with default config values. It
+// is only called while converting a model list, so lookups such as
+// FindDMRModel, which convert models to compare names, stay quiet.
+func warnMissingConfig(dmrModel DMRModel, logger *slog.Logger) {
+	if !hasConfig(dmrModel) {
+		logger.Warn("DMR model has no config, using defaults", "id", dmrModel.ID)
+	}
+}
+
+// familyFromTags returns the family recognized in the first tag's model
+// name, e.g. llama for "ai/llama3.2:1B", or "" if none is recognized
+func familyFromTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	name := tags[0]
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name, _, _ = strings.Cut(name, ":")
+
+	family, _ := determineFamily(name)
+	return family
+}
+
+// expiresAt returns the RFC3339 expiry for a model converted now, or "" when
+// KeepAlive is unset
+func (c *Converter) expiresAt() string {
+	if c.KeepAlive <= 0 {
+		return ""
+	}
+	return time.Now().Add(c.KeepAlive).Format(time.RFC3339)
+}
+
+// ChainTransforms combines several model transforms into one, applied in
+// order. The chain stops as soon as a transform drops the model.
+func ChainTransforms(transforms ...func(OllamaModel) OllamaModel) func(OllamaModel) OllamaModel {
+	return func(model OllamaModel) OllamaModel {
+		for _, transform := range transforms {
+			model = transform(model)
+			if model.Name == "" {
+				return model
+			}
+		}
+		return model
+	}
+}
+
+// RewritePrefix returns a transform that strips the strip prefix from model
+// names and then prepends the add prefix. Either prefix may be empty.
+func RewritePrefix(strip, add string) func(OllamaModel) OllamaModel {
+	return func(model OllamaModel) OllamaModel {
+		model.Name = add + strings.TrimPrefix(model.Name, strip)
+		model.Model = add + strings.TrimPrefix(model.Model, strip)
+		if len(model.Tags) > 0 {
+			tags := make([]string, len(model.Tags))
+			for i, tag := range model.Tags {
+				tags[i] = add + strings.TrimPrefix(tag, strip)
+			}
+			model.Tags = tags
+		}
+		return model
+	}
+}
+
+// NormalizeNames is a transform that lowercases model names. When the name
+// changes, the original is kept in Tags so it isn't lost.
+func NormalizeNames(model OllamaModel) OllamaModel {
+	lower := strings.ToLower(model.Name)
+	if lower != model.Name {
+		model.Tags = append(model.Tags, model.Name)
+	}
+	model.Name = lower
+	model.Model = strings.ToLower(model.Model)
+	return model
+}
+
+// LatestOnly is a ModelTransform that collapses a model's aliases down to
+// a single tag: the ":latest" one when present, otherwise the first.
+// Name and Model both become that tag and the extra Tags are dropped.
+func LatestOnly(model OllamaModel) OllamaModel {
+	tag := LatestTag(append([]string{model.Name}, model.Tags...))
+	model.Name = tag
+	model.Model = tag
+	model.Tags = nil
+	return model
+}
+
+// DropEmptyFamily is a ModelTransform that drops models whose family
+// couldn't be resolved, which confuse clients that group by family
+func DropEmptyFamily(model OllamaModel) OllamaModel {
+	if model.Details.Family == "" {
+		return OllamaModel{}
 	}
+	return model
+}
+
+// LatestTag returns the first tag ending in ":latest", falling back to the
+// first tag, or "" if tags is empty
+func LatestTag(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	alias, _ := splitTags(tags)
+	return alias
+}
+
+// formatCreated formats a Unix timestamp as RFC3339, memoizing in cache if it is non-nil
+func formatCreated(created int64, cache map[int64]string) string {
+	if formatted, ok := cache[created]; ok {
+		return formatted
+	}
+
+	formatted := time.Unix(created, 0).Format(time.RFC3339)
+	if cache != nil {
+		cache[created] = formatted
+	}
+	return formatted
+}
+
+// primaryLayerDigest returns the digest of the model weights layer when DMR
+// reports layers: the first GGUF layer, otherwise the largest one. Without
+// layers it falls back to the first entry of Digests, or "" if neither is set.
+func primaryLayerDigest(dmrModel DMRModel) string {
+	var largest *DMRLayer
+	for i, layer := range dmrModel.Layers {
+		if strings.Contains(strings.ToLower(layer.MediaType), "gguf") {
+			return layer.Digest
+		}
+		if largest == nil || layer.Size > largest.Size {
+			largest = &dmrModel.Layers[i]
+		}
+	}
+	if largest != nil {
+		return largest.Digest
+	}
+
+	if len(dmrModel.Digests) > 0 {
+		return dmrModel.Digests[0]
+	}
+	return ""
+}
+
+// splitTags picks the alias and canonical tags from a model's DMR tags. The
+// alias is the first ":latest" tag and the canonical tag is the first
+// specific one; either falls back to the first tag when not found.
+func splitTags(tags []string) (alias, canonical string) {
+	alias, canonical = tags[0], tags[0]
+	for _, tag := range tags {
+		if strings.HasSuffix(tag, ":latest") {
+			alias = tag
+			break
+		}
+	}
+	for _, tag := range tags {
+		if !strings.HasSuffix(tag, ":latest") {
+			canonical = tag
+			break
+		}
+	}
+	return alias, canonical
+}
+
+// resolveFamily determines the family using the custom resolver if one is
+// set, then the built-in mapping, then DefaultFamily for unrecognized
+// architectures. A blank architecture is guessed from the model's tags.
+func (c *Converter) resolveFamily(config DMRConfig, tags []string) string {
+	if c.FamilyResolver != nil {
+		if family := c.FamilyResolver(config.Architecture, config.Parameters, config.Quantization); family != "" {
+			return family
+		}
+	}
+	if family, ok := c.FamilyMap[strings.ToLower(config.Architecture)]; ok && config.Architecture != "" {
+		return family
+	}
+	if config.Architecture == "" {
+		if family := familyFromTags(tags); family != "" {
+			return family
+		}
+	}
+	if family, ok := determineFamily(config.Architecture); ok {
+		return family
+	}
+	if c.DefaultFamily != "" {
+		return c.DefaultFamily
+	}
+	return config.Architecture
 }
 
 // parseSizeString converts size strings like "690.24 MiB" to bytes
 func parseSizeString(sizeStr string) int64 {
-	// Remove spaces and convert to lowercase
-	sizeStr = strings.ToLower(strings.ReplaceAll(sizeStr, " ", ""))
+	size, _ := parseSize(sizeStr)
+	return size
+}
 
-	// Handle different size units
-	var multiplier int64 = 1
-	if strings.HasSuffix(sizeStr, "gib") {
-		multiplier = 1024 * 1024 * 1024
-		sizeStr = strings.TrimSuffix(sizeStr, "gib")
-	} else if strings.HasSuffix(sizeStr, "mib") {
-		multiplier = 1024 * 1024
-		sizeStr = strings.TrimSuffix(sizeStr, "mib")
-	} else if strings.HasSuffix(sizeStr, "kib") {
-		multiplier = 1024
-		sizeStr = strings.TrimSuffix(sizeStr, "kib")
-	} else if strings.HasSuffix(sizeStr, "b") {
-		multiplier = 1
-		sizeStr = strings.TrimSuffix(sizeStr, "b")
+// parseSize is parseSizeString, also reporting whether sizeStr parsed
+func parseSize(sizeStr string) (int64, bool) {
+	// Remove spaces, skipping the copy when there are none
+	if strings.IndexByte(sizeStr, ' ') >= 0 {
+		sizeStr = strings.ReplaceAll(sizeStr, " ", "")
 	}
 
+	// Units are matched ignoring ASCII case and ParseFloat ignores case too,
+	// so only non-ASCII input, where lowercasing can turn other characters
+	// into ASCII letters, needs to be lowercased up front
+	if !isASCII(sizeStr) {
+		sizeStr = strings.ToLower(sizeStr)
+	}
+
+	// Handle different size units
+	number, multiplier := splitSizeUnit(sizeStr)
+
 	// Parse the numeric value
-	size, err := strconv.ParseFloat(sizeStr, 64)
+	size, err := strconv.ParseFloat(number, 64)
 	if err != nil {
-		return 0
+		return 0, false
+	}
+
+	return int64(size * float64(multiplier)), true
+}
+
+// splitSizeUnit splits a GiB, MiB, KiB or B suffix off s in a single pass
+// over its last bytes, returning the number before it and its multiplier
+func splitSizeUnit(s string) (string, int64) {
+	n := len(s)
+	if n == 0 || lowerASCII(s[n-1]) != 'b' {
+		return s, 1
+	}
+
+	if n >= 3 && lowerASCII(s[n-2]) == 'i' {
+		switch lowerASCII(s[n-3]) {
+		case 'g':
+			return s[:n-3], 1024 * 1024 * 1024
+		case 'm':
+			return s[:n-3], 1024 * 1024
+		case 'k':
+			return s[:n-3], 1024
+		}
 	}
 
-	return int64(size * float64(multiplier))
+	return s[:n-1], 1
 }
 
-// determineFamily maps architecture to family
-func determineFamily(architecture string) string {
-	switch strings.ToLower(architecture) {
-	case "llama", "llama2", "llama3":
-		return "llama"
-	case "phi3", "phi4":
-		return "phi3"
-	case "qwen", "qwen3":
-		return "qwen"
-	default:
-		return architecture
+// lowerASCII lowercases an ASCII letter, leaving other bytes unchanged
+func lowerASCII(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + ('a' - 'A')
+	}
+	return b
+}
+
+// isASCII reports whether s contains only ASCII bytes
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// familyPrefixes maps normalized architecture prefixes to families. Prefixes
+// must not overlap other families, e.g. "llava" must not match "llama".
+var familyPrefixes = []struct {
+	prefix string
+	family string
+}{
+	{"llama", "llama"},
+	{"phi3", "phi3"},
+	{"phi4", "phi3"},
+	{"qwen", "qwen"},
+	{"llava", "llava"},
+	{"bakllava", "llava"},
+	{"moondream", "moondream"},
+}
+
+// architectureSeparators strips the separators determineFamily ignores,
+// built once as determineFamily runs several times per model
+var architectureSeparators = strings.NewReplacer("-", "", ".", "")
+
+// determineFamily maps architecture to family, reporting false for
+// architectures it doesn't recognize. Matching ignores case, hyphens and
+// dots, so "Llama-3" and "llama3.1" both map to llama.
+func determineFamily(architecture string) (string, bool) {
+	normalized := architectureSeparators.Replace(strings.ToLower(architecture))
+	for _, entry := range familyPrefixes {
+		if strings.HasPrefix(normalized, entry.prefix) {
+			return entry.family, true
+		}
 	}
+	return "", false
 }
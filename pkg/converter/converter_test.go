@@ -1,9 +1,16 @@
 package converter
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -29,6 +36,44 @@ func TestNewConverterWithClient(t *testing.T) {
 	}
 }
 
+// closeTrackingTransport records whether CloseIdleConnections was called
+type closeTrackingTransport struct {
+	http.RoundTripper
+	closed bool
+}
+
+func (t *closeTrackingTransport) CloseIdleConnections() {
+	t.closed = true
+}
+
+func TestClose(t *testing.T) {
+	if err := NewConverter().Close(); err != nil {
+		t.Errorf("Expected no error closing default converter, got %v", err)
+	}
+
+	transport := &closeTrackingTransport{RoundTripper: http.DefaultTransport}
+	conv := NewConverterWithClient(&http.Client{Transport: transport})
+	if err := conv.Close(); err != nil {
+		t.Errorf("Expected no error closing custom converter, got %v", err)
+	}
+	if !transport.closed {
+		t.Error("Expected CloseIdleConnections to be called on the custom transport")
+	}
+
+	// Transports without CloseIdleConnections are left alone
+	conv = NewConverterWithClient(&http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)})
+	if err := conv.Close(); err != nil {
+		t.Errorf("Expected no error closing plain transport, got %v", err)
+	}
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
 func TestConvertFromURL(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -272,3 +317,1138 @@ func TestOllamaResponseJSON(t *testing.T) {
 		t.Errorf("Expected 1 model in parsed response, got %d", len(parsedResponse.Models))
 	}
 }
+
+// newDelayedDMRServer starts a stub DMR server that waits before returning a single model
+func newDelayedDMRServer(t *testing.T, tag string, delay time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"id": "sha256:%s", "tags": ["%s"], "created": 1745698622, "config": {"architecture": "llama", "size": "1 GiB"}}]`, tag, tag)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestConvertFromURLsConcurrent(t *testing.T) {
+	delay := 200 * time.Millisecond
+	var urls []string
+	for i := 0; i < 3; i++ {
+		server := newDelayedDMRServer(t, fmt.Sprintf("model%d", i), delay)
+		urls = append(urls, server.URL+"/models")
+	}
+
+	conv := NewConverter()
+	start := time.Now()
+	response, err := conv.ConvertFromURLs(context.Background(), urls)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Fetching sequentially would take at least 3x the delay
+	if elapsed >= 2*delay {
+		t.Errorf("Expected fetches to run concurrently in about %v, took %v", delay, elapsed)
+	}
+
+	if len(response.Models) != 3 {
+		t.Fatalf("Expected 3 models, got %d", len(response.Models))
+	}
+
+	// Models should keep the order of the URLs
+	for i, model := range response.Models {
+		expectedName := fmt.Sprintf("model%d", i)
+		if model.Name != expectedName {
+			t.Errorf("Expected model %d to be '%s', got '%s'", i, expectedName, model.Name)
+		}
+	}
+}
+
+func TestConvertFromURLsPartialError(t *testing.T) {
+	good := newDelayedDMRServer(t, "model1", 0)
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+
+	conv := NewConverter()
+	response, err := conv.ConvertFromURLs(context.Background(), []string{good.URL, bad.URL})
+	if err == nil {
+		t.Error("Expected error for failing server, got nil")
+	}
+
+	if len(response.Models) != 1 {
+		t.Errorf("Expected 1 model from the healthy server, got %d", len(response.Models))
+	}
+}
+
+func TestConvertFromURLsCancelled(t *testing.T) {
+	server := newDelayedDMRServer(t, "model1", 500*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	conv := NewConverter()
+	_, err := conv.ConvertFromURLs(ctx, []string{server.URL})
+	if err == nil {
+		t.Error("Expected error for cancelled context, got nil")
+	}
+}
+
+func TestDetermineFamily(t *testing.T) {
+	tests := []struct {
+		architecture string
+		family       string
+		known        bool
+	}{
+		{"llama", "llama", true},
+		{"Llama-3", "llama", true},
+		{"LLAMA3.1", "llama", true},
+		{"llama3.1", "llama", true},
+		{"phi-4", "phi3", true},
+		{"Qwen2.5", "qwen", true},
+		{"llava", "llava", true},
+		{"acme-net", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		family, known := determineFamily(tt.architecture)
+		if family != tt.family || known != tt.known {
+			t.Errorf("determineFamily(%q) = (%q, %v), expected (%q, %v)", tt.architecture, family, known, tt.family, tt.known)
+		}
+	}
+}
+
+func TestConvertModelWithoutConfig(t *testing.T) {
+	var dmrModels []DMRModel
+	err := json.Unmarshal([]byte(`[{"id": "sha256:test1", "tags": ["ai/llama3.2:1B-Q8_0"], "created": 1745698622}]`), &dmrModels)
+	if err != nil {
+		t.Fatalf("Expected valid DMR JSON, got %v", err)
+	}
+
+	var logs bytes.Buffer
+	conv := NewConverter()
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	result := conv.ConvertDMRToOllama(dmrModels)
+	details := result.Models[0].Details
+	if details.Format != "gguf" {
+		t.Errorf("Expected default format 'gguf', got '%s'", details.Format)
+	}
+
+	if details.Family != "llama" {
+		t.Errorf("Expected family 'llama' from the tag name, got '%s'", details.Family)
+	}
+
+	if !strings.Contains(logs.String(), "no config") {
+		t.Errorf("Expected missing config warning, got %q", logs.String())
+	}
+
+	// Unrecognizable names still get the default format
+	result = conv.ConvertDMRToOllama([]DMRModel{{ID: "sha256:test2", Tags: []string{"ai/smollm2"}}})
+	if result.Models[0].Details.Format != "gguf" || result.Models[0].Details.Family != "" {
+		t.Errorf("Expected format 'gguf' and no family, got %+v", result.Models[0].Details)
+	}
+
+	// Finding a model converts it too, but shouldn't repeat the warning
+	logs.Reset()
+	conv.FindDMRModel(dmrModels, "ai/llama3.2:1B-Q8_0")
+	if logs.Len() != 0 {
+		t.Errorf("Expected no warning when finding a model, got %q", logs.String())
+	}
+}
+
+func TestFamilyFromName(t *testing.T) {
+	conv := NewConverter()
+	result := conv.ConvertDMRToOllama([]DMRModel{
+		{ID: "sha256:test1", Tags: []string{"llama3:8b"}, Config: DMRConfig{Format: "gguf", Parameters: "8B"}},
+		{ID: "sha256:test2", Tags: []string{"ai/qwen3:latest"}, Config: DMRConfig{Format: "gguf"}},
+		{ID: "sha256:test3", Tags: []string{"ai/llama3:8b"}, Config: DMRConfig{Architecture: "phi3"}},
+	})
+
+	if result.Models[0].Details.Family != "llama" {
+		t.Errorf("Expected family 'llama' from the name, got '%s'", result.Models[0].Details.Family)
+	}
+
+	if result.Models[1].Details.Family != "qwen" {
+		t.Errorf("Expected family 'qwen' from the name, got '%s'", result.Models[1].Details.Family)
+	}
+
+	// Architecture wins over the name when present
+	if result.Models[2].Details.Family != "phi3" {
+		t.Errorf("Expected family 'phi3' from the architecture, got '%s'", result.Models[2].Details.Family)
+	}
+}
+
+func TestDefaultFamily(t *testing.T) {
+	conv := NewConverter()
+	conv.DefaultFamily = "llama"
+
+	result := conv.ConvertDMRToOllama([]DMRModel{
+		{ID: "sha256:test1", Tags: []string{"model1"}, Config: DMRConfig{Architecture: "acme-net"}},
+		{ID: "sha256:test2", Tags: []string{"model2"}, Config: DMRConfig{Architecture: "qwen3"}},
+	})
+
+	if result.Models[0].Details.Family != "llama" {
+		t.Errorf("Expected default family 'llama' for unknown architecture, got '%s'", result.Models[0].Details.Family)
+	}
+
+	// Recognized architectures keep their mapped family
+	if result.Models[1].Details.Family != "qwen" {
+		t.Errorf("Expected family 'qwen', got '%s'", result.Models[1].Details.Family)
+	}
+
+	// Without a default, unknown architectures pass through
+	conv.DefaultFamily = ""
+	result = conv.ConvertDMRToOllama([]DMRModel{{ID: "sha256:test1", Config: DMRConfig{Architecture: "acme-net"}}})
+	if result.Models[0].Details.Family != "acme-net" {
+		t.Errorf("Expected raw architecture 'acme-net', got '%s'", result.Models[0].Details.Family)
+	}
+}
+
+func TestFamilyMap(t *testing.T) {
+	conv := NewConverter()
+	conv.FamilyMap = map[string]string{"acme-net": "acme", "llama": "custom-llama"}
+
+	result := conv.ConvertDMRToOllama([]DMRModel{
+		{ID: "sha256:test1", Tags: []string{"model1"}, Config: DMRConfig{Architecture: "ACME-Net"}},
+		{ID: "sha256:test2", Tags: []string{"model2"}, Config: DMRConfig{Architecture: "llama"}},
+		{ID: "sha256:test3", Tags: []string{"model3"}, Config: DMRConfig{Architecture: "qwen3"}},
+	})
+
+	expected := []string{"acme", "custom-llama", "qwen"}
+	for i, family := range expected {
+		if result.Models[i].Details.Family != family {
+			t.Errorf("Expected family '%s' for model %d, got '%s'", family, i, result.Models[i].Details.Family)
+		}
+	}
+}
+
+func TestFamilyResolver(t *testing.T) {
+	conv := NewConverter()
+	conv.FamilyResolver = func(architecture, parameters, quantization string) string {
+		if architecture == "acme-net" {
+			return "acme"
+		}
+		return ""
+	}
+
+	result := conv.ConvertDMRToOllama([]DMRModel{
+		{ID: "sha256:test1", Tags: []string{"model1"}, Config: DMRConfig{Architecture: "acme-net"}},
+		{ID: "sha256:test2", Tags: []string{"model2"}, Config: DMRConfig{Architecture: "llama3"}},
+	})
+
+	if result.Models[0].Details.Family != "acme" {
+		t.Errorf("Expected custom family 'acme', got '%s'", result.Models[0].Details.Family)
+	}
+
+	if result.Models[0].Details.Families[0] != "acme" {
+		t.Errorf("Expected custom families ['acme'], got %v", result.Models[0].Details.Families)
+	}
+
+	// Empty resolver result falls back to the built-in mapping
+	if result.Models[1].Details.Family != "llama" {
+		t.Errorf("Expected fallback family 'llama', got '%s'", result.Models[1].Details.Family)
+	}
+}
+
+func TestModelTransformRename(t *testing.T) {
+	conv := NewConverter()
+	conv.ModelTransform = func(model OllamaModel) OllamaModel {
+		model.Name = strings.ToLower(strings.TrimPrefix(model.Name, "ai/"))
+		model.Model = model.Name
+		return model
+	}
+
+	result := conv.ConvertDMRToOllama([]DMRModel{
+		{ID: "sha256:test1", Tags: []string{"ai/SmolLM2:360M"}},
+	})
+
+	if len(result.Models) != 1 {
+		t.Fatalf("Expected 1 model, got %d", len(result.Models))
+	}
+
+	if result.Models[0].Name != "smollm2:360m" {
+		t.Errorf("Expected transformed name 'smollm2:360m', got '%s'", result.Models[0].Name)
+	}
+
+	if result.Models[0].Model != "smollm2:360m" {
+		t.Errorf("Expected transformed model 'smollm2:360m', got '%s'", result.Models[0].Model)
+	}
+}
+
+func TestModelTransformDrop(t *testing.T) {
+	conv := NewConverter()
+	conv.ModelTransform = func(model OllamaModel) OllamaModel {
+		if model.Name == "model2" {
+			return OllamaModel{}
+		}
+		return model
+	}
+
+	result := conv.ConvertDMRToOllama([]DMRModel{
+		{ID: "sha256:test1", Tags: []string{"model1"}},
+		{ID: "sha256:test2", Tags: []string{"model2"}},
+	})
+
+	if len(result.Models) != 1 {
+		t.Fatalf("Expected 1 model after drop, got %d", len(result.Models))
+	}
+
+	if result.Models[0].Name != "model1" {
+		t.Errorf("Expected remaining model 'model1', got '%s'", result.Models[0].Name)
+	}
+}
+
+func TestRewritePrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		strip    string
+		add      string
+		input    string
+		expected string
+	}{
+		{"strip", "ai/", "", "ai/smollm2:360M-F16", "smollm2:360M-F16"},
+		{"add", "", "team/", "ai/smollm2:360M-F16", "team/ai/smollm2:360M-F16"},
+		{"strip and add", "ai/", "team/", "ai/smollm2:360M-F16", "team/smollm2:360M-F16"},
+		{"strip missing prefix", "hub/", "", "ai/smollm2:360M-F16", "ai/smollm2:360M-F16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RewritePrefix(tt.strip, tt.add)(OllamaModel{Name: tt.input, Model: tt.input})
+			if result.Name != tt.expected {
+				t.Errorf("Expected name '%s', got '%s'", tt.expected, result.Name)
+			}
+			if result.Model != tt.expected {
+				t.Errorf("Expected model '%s', got '%s'", tt.expected, result.Model)
+			}
+		})
+	}
+}
+
+func TestChainTransforms(t *testing.T) {
+	calls := 0
+	count := func(model OllamaModel) OllamaModel {
+		calls++
+		return model
+	}
+	drop := func(model OllamaModel) OllamaModel {
+		return OllamaModel{}
+	}
+
+	result := ChainTransforms(RewritePrefix("ai/", ""), count)(OllamaModel{Name: "ai/model1", Model: "ai/model1"})
+	if result.Name != "model1" || calls != 1 {
+		t.Errorf("Expected all transforms applied, got name '%s' and %d calls", result.Name, calls)
+	}
+
+	// Transforms after a drop are skipped
+	calls = 0
+	result = ChainTransforms(drop, count)(OllamaModel{Name: "model1"})
+	if result.Name != "" || calls != 0 {
+		t.Errorf("Expected chain to stop after drop, got name '%s' and %d calls", result.Name, calls)
+	}
+}
+
+func TestNormalizeNames(t *testing.T) {
+	result := NormalizeNames(OllamaModel{Name: "ai/Llama3:8B", Model: "ai/Llama3:8B"})
+
+	if result.Name != "ai/llama3:8b" {
+		t.Errorf("Expected name 'ai/llama3:8b', got '%s'", result.Name)
+	}
+
+	if result.Model != "ai/llama3:8b" {
+		t.Errorf("Expected model 'ai/llama3:8b', got '%s'", result.Model)
+	}
+
+	if len(result.Tags) != 1 || result.Tags[0] != "ai/Llama3:8B" {
+		t.Errorf("Expected original name kept in tags, got %v", result.Tags)
+	}
+
+	// Already-lowercase names don't gain a duplicate tag
+	result = NormalizeNames(OllamaModel{Name: "model1", Model: "model1"})
+	if len(result.Tags) != 0 {
+		t.Errorf("Expected no tags for unchanged name, got %v", result.Tags)
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	tests := []struct {
+		tags     []string
+		expected string
+	}{
+		{[]string{"llama3:8b-q4", "llama3:8b-q8", "llama3:latest"}, "llama3:latest"},
+		{[]string{"llama3:8b-q4", "llama3:8b-q8"}, "llama3:8b-q4"},
+		{nil, ""},
+	}
+
+	for _, test := range tests {
+		if result := LatestTag(test.tags); result != test.expected {
+			t.Errorf("LatestTag(%v) = '%s', expected '%s'", test.tags, result, test.expected)
+		}
+	}
+}
+
+func TestLatestOnly(t *testing.T) {
+	// A digest with a :latest tag keeps only that alias
+	conv := NewConverter()
+	conv.ModelTransform = LatestOnly
+	result := conv.ConvertDMRToOllama([]DMRModel{
+		{ID: "sha256:test1", Tags: []string{"llama3:8b-q4", "llama3:8b-q8", "llama3:latest"}},
+	})
+	model := result.Models[0]
+	if model.Name != "llama3:latest" || model.Model != "llama3:latest" {
+		t.Errorf("Expected name and model 'llama3:latest', got '%s' and '%s'", model.Name, model.Model)
+	}
+
+	// Without a :latest tag, merged aliases collapse to the first name
+	model = LatestOnly(OllamaModel{Name: "llama3:8b-q4", Model: "llama3:8b-q4", Tags: []string{"llama3:8b-q8"}})
+	if model.Name != "llama3:8b-q4" || model.Model != "llama3:8b-q4" {
+		t.Errorf("Expected name and model 'llama3:8b-q4', got '%s' and '%s'", model.Name, model.Model)
+	}
+
+	if len(model.Tags) != 0 {
+		t.Errorf("Expected tags to be dropped, got %v", model.Tags)
+	}
+
+	// A :latest alias recorded in tags wins over the name
+	model = LatestOnly(OllamaModel{Name: "llama3:8b-q4", Tags: []string{"llama3:latest"}})
+	if model.Name != "llama3:latest" {
+		t.Errorf("Expected name 'llama3:latest', got '%s'", model.Name)
+	}
+}
+
+func TestFetchDMRModelsConditional(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	conv.ConditionalRequests = true
+
+	first, err := conv.FetchDMRModels(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error on first fetch, got %v", err)
+	}
+
+	second, err := conv.FetchDMRModels(server.URL)
+	if err != nil {
+		t.Fatalf("Expected 304 to not be an error, got %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+
+	if len(second) != 1 || second[0].ID != first[0].ID {
+		t.Errorf("Expected cached models to be reused, got %v", second)
+	}
+}
+
+func TestFetchDMRModelsNotModifiedWithoutCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	// A 304 without a previous fetch has nothing to reuse
+	conv := NewConverter()
+	conv.ConditionalRequests = true
+	_, err := conv.FetchDMRModels(server.URL)
+	if err == nil {
+		t.Error("Expected error for 304 without cached result, got nil")
+	}
+}
+
+func TestConvertOllamaToDMRRoundTrip(t *testing.T) {
+	original := []DMRModel{
+		{
+			ID:      "sha256:020ef929a2866cc4079bf477583c23dc1432e37b9e73b3c20de51a3720b90ac7",
+			Tags:    []string{"ai/smollm2:360M-F16"},
+			Created: 1745698622,
+			Config: DMRConfig{
+				Format:       "gguf",
+				Quantization: "F16",
+				Parameters:   "361.82 M",
+				Architecture: "llama",
+				Size:         "690.24 MiB",
+			},
+		},
+	}
+
+	conv := NewConverter()
+	roundTrip := conv.ConvertOllamaToDMR(conv.ConvertDMRToOllama(original))
+
+	if len(roundTrip) != 1 {
+		t.Fatalf("Expected 1 model, got %d", len(roundTrip))
+	}
+
+	model := roundTrip[0]
+	if model.ID != original[0].ID {
+		t.Errorf("Expected ID '%s', got '%s'", original[0].ID, model.ID)
+	}
+	if len(model.Tags) != 1 || model.Tags[0] != original[0].Tags[0] {
+		t.Errorf("Expected tags %v, got %v", original[0].Tags, model.Tags)
+	}
+	if model.Created != original[0].Created {
+		t.Errorf("Expected created %d, got %d", original[0].Created, model.Created)
+	}
+	if !reflect.DeepEqual(model.Config, original[0].Config) {
+		t.Errorf("Expected config %+v, got %+v", original[0].Config, model.Config)
+	}
+}
+
+func TestConvertSingleModelAliasAndCanonicalTags(t *testing.T) {
+	conv := NewConverter()
+
+	tests := []struct {
+		name          string
+		tags          []string
+		expectedName  string
+		expectedModel string
+	}{
+		{"alias and canonical", []string{"ai/llama3:8B-Q4_K_M", "ai/llama3:latest"}, "ai/llama3:latest", "ai/llama3:8B-Q4_K_M"},
+		{"single tag", []string{"ai/llama3:8B-Q4_K_M"}, "ai/llama3:8B-Q4_K_M", "ai/llama3:8B-Q4_K_M"},
+		{"only latest", []string{"ai/phi4:latest"}, "ai/phi4:latest", "ai/phi4:latest"},
+		{"no latest", []string{"ai/qwen3:8B-F16", "ai/qwen3:8B"}, "ai/qwen3:8B-F16", "ai/qwen3:8B-F16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := conv.convertSingleModel(DMRModel{ID: "sha256:test1", Tags: tt.tags})
+			if result.Name != tt.expectedName {
+				t.Errorf("Expected name '%s', got '%s'", tt.expectedName, result.Name)
+			}
+			if result.Model != tt.expectedModel {
+				t.Errorf("Expected model '%s', got '%s'", tt.expectedModel, result.Model)
+			}
+		})
+	}
+}
+
+func TestConvertFromJSONLayers(t *testing.T) {
+	jsonData := []byte(`[
+		{
+			"id": "sha256:manifest",
+			"tags": ["model1"],
+			"created": 1745698622,
+			"config": {"architecture": "llama", "size": "1 GiB"},
+			"layers": [
+				{"digest": "sha256:license", "mediaType": "application/vnd.docker.ai.license", "size": 100},
+				{"digest": "sha256:weights", "mediaType": "application/vnd.docker.ai.gguf.v3", "size": 1073741824}
+			]
+		},
+		{
+			"id": "sha256:manifest2",
+			"tags": ["model2"],
+			"digests": ["sha256:first", "sha256:second"]
+		},
+		{
+			"id": "sha256:manifest3",
+			"tags": ["model3"]
+		}
+	]`)
+
+	conv := NewConverter()
+	response, err := conv.ConvertFromJSON(jsonData)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"weights", "first", "manifest3"}
+	for i, digest := range expected {
+		if response.Models[i].Digest != digest {
+			t.Errorf("Expected model %d digest '%s', got '%s'", i, digest, response.Models[i].Digest)
+		}
+	}
+}
+
+func TestPrimaryLayerDigestLargest(t *testing.T) {
+	model := DMRModel{
+		Layers: []DMRLayer{
+			{Digest: "sha256:small", Size: 10},
+			{Digest: "sha256:large", Size: 1000},
+		},
+	}
+
+	if digest := primaryLayerDigest(model); digest != "sha256:large" {
+		t.Errorf("Expected largest layer digest, got '%s'", digest)
+	}
+}
+
+func TestConvertFromURLsServerTimeout(t *testing.T) {
+	fast := newDelayedDMRServer(t, "model1", 0)
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hanging.Close()
+
+	var logs bytes.Buffer
+	conv := NewConverter()
+	conv.ServerTimeout = 100 * time.Millisecond
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	start := time.Now()
+	response, err := conv.ConvertFromURLs(context.Background(), []string{fast.URL, hanging.URL})
+	if err != nil {
+		t.Fatalf("Expected slow server to be skipped without error, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the slow server to be cut off, took %v", elapsed)
+	}
+
+	if len(response.Models) != 1 || response.Models[0].Name != "model1" {
+		t.Errorf("Expected only the fast server's model, got %v", response.Models)
+	}
+
+	if !strings.Contains(logs.String(), hanging.URL) {
+		t.Errorf("Expected skipped server to be logged, got %q", logs.String())
+	}
+}
+
+func TestConvertFromURLsAllServersTimeout(t *testing.T) {
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hanging.Close()
+
+	conv := NewConverter()
+	conv.ServerTimeout = 50 * time.Millisecond
+
+	_, err := conv.ConvertFromURLs(context.Background(), []string{hanging.URL, hanging.URL})
+	if !errors.Is(err, ErrFetch) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a fetch error when no server finished in time, got %v", err)
+	}
+}
+
+// largeDMRJSON builds a DMR model list with n models
+func largeDMRJSON(n int) []byte {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"id": "sha256:%d", "tags": ["model%d"], "created": 1745698622, "config": {"architecture": "llama", "size": "1 GiB"}}`, i, i)
+	}
+	b.WriteString("]")
+	return []byte(b.String())
+}
+
+func TestConvertFromJSONContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel part way through the conversion
+	converted := 0
+	conv := NewConverter()
+	conv.ModelTransform = func(model OllamaModel) OllamaModel {
+		converted++
+		if converted == 100 {
+			cancel()
+		}
+		return model
+	}
+
+	_, err := conv.ConvertFromJSONContext(ctx, largeDMRJSON(10000))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if converted != 100 {
+		t.Errorf("Expected conversion to stop after 100 models, converted %d", converted)
+	}
+}
+
+func TestConvertFromJSONContext(t *testing.T) {
+	conv := NewConverter()
+	response, err := conv.ConvertFromJSONContext(context.Background(), largeDMRJSON(3))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(response.Models) != 3 {
+		t.Errorf("Expected 3 models, got %d", len(response.Models))
+	}
+}
+
+// benchmarkDMRModels builds n DMR models for benchmarks
+func benchmarkDMRModels(n int) []DMRModel {
+	dmrModels := make([]DMRModel, n)
+	for i := range dmrModels {
+		dmrModels[i] = DMRModel{
+			ID:      fmt.Sprintf("sha256:%064d", i),
+			Tags:    []string{fmt.Sprintf("ai/model%d:latest", i)},
+			Created: 1745698622 + int64(i),
+			Config: DMRConfig{
+				Format:       "gguf",
+				Quantization: "Q4_K_M",
+				Parameters:   "8B",
+				Architecture: "llama",
+				Size:         "4.58 GiB",
+			},
+		}
+	}
+	return dmrModels
+}
+
+func BenchmarkConvertDMRToOllama(b *testing.B) {
+	dmrModels := benchmarkDMRModels(10000)
+	conv := NewConverter()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		conv.ConvertDMRToOllama(dmrModels)
+	}
+}
+
+func BenchmarkConvertDMRToOllamaDuplicateTimestamps(b *testing.B) {
+	dmrModels := benchmarkDMRModels(10000)
+	for i := range dmrModels {
+		dmrModels[i].Created = 1745698622 + int64(i%10)
+	}
+	conv := NewConverter()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		conv.ConvertDMRToOllama(dmrModels)
+	}
+}
+
+func TestMaxModels(t *testing.T) {
+	var logs bytes.Buffer
+	conv := NewConverter()
+	conv.MaxModels = 2
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	result := conv.ConvertDMRToOllama(benchmarkDMRModels(5))
+	if len(result.Models) != 2 {
+		t.Fatalf("Expected output capped at 2 models, got %d", len(result.Models))
+	}
+
+	if result.Models[1].Name != "ai/model1:latest" {
+		t.Errorf("Expected the first models to be kept, got '%s'", result.Models[1].Name)
+	}
+
+	if !strings.Contains(logs.String(), "truncating converted models") {
+		t.Errorf("Expected truncation warning, got %q", logs.String())
+	}
+
+	// No warning when the list fits
+	logs.Reset()
+	conv.ConvertDMRToOllama(benchmarkDMRModels(2))
+	if logs.Len() != 0 {
+		t.Errorf("Expected no warning without truncation, got %q", logs.String())
+	}
+
+	// Streaming applies the same cap
+	var streamed bytes.Buffer
+	conv.StreamConvert(&streamed, benchmarkDMRModels(5))
+	var response OllamaResponse
+	json.Unmarshal(streamed.Bytes(), &response)
+	if len(response.Models) != 2 {
+		t.Errorf("Expected streamed output capped at 2 models, got %d", len(response.Models))
+	}
+}
+
+func TestKeepAliveExpiresAt(t *testing.T) {
+	dmrModels := []DMRModel{{ID: "sha256:test1", Tags: []string{"model1"}}}
+
+	// Omitted by default
+	conv := NewConverter()
+	result := conv.ConvertDMRToOllama(dmrModels)
+	jsonData, _ := json.Marshal(result)
+	if strings.Contains(string(jsonData), "expires_at") {
+		t.Errorf("Expected no expires_at without KeepAlive, got %s", jsonData)
+	}
+
+	conv.KeepAlive = 5 * time.Minute
+	before := time.Now().Truncate(time.Second)
+	result = conv.ConvertDMRToOllama(dmrModels)
+	after := time.Now()
+
+	expiresAt, err := time.Parse(time.RFC3339, result.Models[0].ExpiresAt)
+	if err != nil {
+		t.Fatalf("Expected RFC3339 expires_at, got '%s': %v", result.Models[0].ExpiresAt, err)
+	}
+
+	if expiresAt.Before(before.Add(conv.KeepAlive)) || expiresAt.After(after.Add(conv.KeepAlive)) {
+		t.Errorf("Expected expires_at about 5m from now, got %s", expiresAt)
+	}
+}
+
+func TestConvertFromJSONNumericSize(t *testing.T) {
+	tests := map[string]string{
+		"string":  `"1 GiB"`,
+		"number":  `1073741824`,
+		"missing": ``,
+	}
+
+	for name, size := range tests {
+		config := `{"architecture": "llama"}`
+		if size != "" {
+			config = `{"architecture": "llama", "size": ` + size + `}`
+		}
+		jsonData := []byte(`[{"id": "sha256:test1", "tags": ["model1"], "config": ` + config + `}]`)
+
+		conv := NewConverter()
+		response, err := conv.ConvertFromJSON(jsonData)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+
+		expected := int64(1073741824)
+		if size == "" {
+			expected = 0
+		}
+		if response.Models[0].Size != expected {
+			t.Errorf("%s: expected size %d, got %d", name, expected, response.Models[0].Size)
+		}
+	}
+
+	conv := NewConverter()
+	_, err := conv.ConvertFromJSON([]byte(`[{"id": "sha256:test1", "config": {"size": true}}]`))
+	if err == nil {
+		t.Error("Expected error for boolean size, got nil")
+	}
+}
+
+func TestConvertFromJSONCreatedFormats(t *testing.T) {
+	tests := map[string]string{
+		"unix":         `1745698622`,
+		"rfc3339":      `"2025-04-26T20:17:02Z"`,
+		"rfc3339 nano": `"2025-04-26T22:17:02.123456789+02:00"`,
+	}
+
+	for name, created := range tests {
+		jsonData := []byte(`[{"id": "sha256:test1", "tags": ["model1"], "created": ` + created + `}]`)
+
+		conv := NewConverter()
+		response, err := conv.ConvertFromJSON(jsonData)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+
+		expected := time.Unix(1745698622, 0).UTC().Format(time.RFC3339)
+		modifiedAt, err := time.Parse(time.RFC3339, response.Models[0].ModifiedAt)
+		if err != nil || modifiedAt.UTC().Format(time.RFC3339) != expected {
+			t.Errorf("%s: expected modified_at %s, got '%s'", name, expected, response.Models[0].ModifiedAt)
+		}
+	}
+
+	conv := NewConverter()
+	_, err := conv.ConvertFromJSON([]byte(`[{"id": "sha256:test1", "created": "yesterday"}]`))
+	if err == nil {
+		t.Error("Expected error for invalid created timestamp, got nil")
+	}
+}
+
+func TestIncludeSizeString(t *testing.T) {
+	dmrModels := []DMRModel{{ID: "sha256:test1", Tags: []string{"model1"}, Config: DMRConfig{Size: "1.5 GiB"}}}
+
+	conv := NewConverter()
+	result := conv.ConvertDMRToOllama(dmrModels)
+	jsonData, _ := json.Marshal(result)
+	if strings.Contains(string(jsonData), "size_string") {
+		t.Errorf("Expected no size_string by default, got %s", jsonData)
+	}
+
+	conv.IncludeSizeString = true
+	result = conv.ConvertDMRToOllama(dmrModels)
+	if result.Models[0].SizeString != "1.5 GiB" {
+		t.Errorf("Expected size_string '1.5 GiB', got '%s'", result.Models[0].SizeString)
+	}
+	if result.Models[0].Size != 1610612736 {
+		t.Errorf("Expected size 1610612736, got %d", result.Models[0].Size)
+	}
+}
+
+func TestDropEmptyFamily(t *testing.T) {
+	dmrModels := []DMRModel{
+		{ID: "sha256:test1", Tags: []string{"ai/llama3.2:1B"}, Config: DMRConfig{Format: "gguf"}},
+		{ID: "sha256:test2", Tags: []string{"ai/mystery:1B"}, Config: DMRConfig{Format: "gguf"}},
+	}
+
+	conv := NewConverter()
+	result := conv.ConvertDMRToOllama(dmrModels)
+	if len(result.Models) != 2 {
+		t.Fatalf("Expected 2 models by default, got %d", len(result.Models))
+	}
+	if result.Models[1].Details.Family != "" {
+		t.Fatalf("Expected an empty family for the unknown model, got '%s'", result.Models[1].Details.Family)
+	}
+
+	conv.ModelTransform = DropEmptyFamily
+	result = conv.ConvertDMRToOllama(dmrModels)
+	if len(result.Models) != 1 || result.Models[0].Name != "ai/llama3.2:1B" {
+		t.Errorf("Expected only the llama model, got %+v", result.Models)
+	}
+}
+
+func TestPreserveUnknownFields(t *testing.T) {
+	data := []byte(`[{"id": "sha256:test1", "tags": ["model1"], "config": {"format": "gguf", "size": "1 GiB", "rope_scaling": {"factor": 2}}}]`)
+
+	conv := NewConverter()
+	result, err := conv.ConvertFromJSON(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Models[0].Extra != nil {
+		t.Errorf("Expected no extra by default, got %v", result.Models[0].Extra)
+	}
+
+	conv.PreserveUnknownFields = true
+	result, err = conv.ConvertFromJSON(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	extra := result.Models[0].Extra
+	if len(extra) != 1 {
+		t.Fatalf("Expected 1 extra field, got %v", extra)
+	}
+	expected := map[string]any{"factor": float64(2)}
+	if !reflect.DeepEqual(extra["rope_scaling"], expected) {
+		t.Errorf("Expected rope_scaling %v, got %v", expected, extra["rope_scaling"])
+	}
+	if result.Models[0].Details.Format != "gguf" {
+		t.Errorf("Expected format 'gguf', got '%s'", result.Models[0].Details.Format)
+	}
+}
+
+func TestParseSizeString(t *testing.T) {
+	tests := map[string]int64{
+		"1 GiB":      1073741824,
+		"4.58 GiB":   4917737553,
+		"1gib":       1073741824,
+		"1GIB":       1073741824,
+		"512 MiB":    536870912,
+		"1.5 mib":    1572864,
+		"2 KiB":      2048,
+		"100 B":      100,
+		"100b":       100,
+		"1073741824": 1073741824,
+		"1e3":        1000,
+		"1E3 KiB":    1024000,
+		" 1 0 MiB ":  10485760,
+		"1 GB":       0,
+		"1 TiB":      0,
+		"ib":         0,
+		"b":          0,
+		"":           0,
+		"large":      0,
+		"1 \u212aiB": 1024,
+	}
+
+	for input, expected := range tests {
+		if result := parseSizeString(input); result != expected {
+			t.Errorf("parseSizeString(%q) = %d, expected %d", input, result, expected)
+		}
+	}
+}
+
+func BenchmarkParseSizeString(b *testing.B) {
+	inputs := []string{"4.58 GiB", "512MiB", "1073741824", "100 B"}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, input := range inputs {
+			parseSizeString(input)
+		}
+	}
+}
+
+func TestDigestCase(t *testing.T) {
+	dmrModels := []DMRModel{{ID: "sha256:ABCDEF0123", Tags: []string{"model1"}}}
+
+	conv := NewConverter()
+	result := conv.ConvertDMRToOllama(dmrModels)
+	if result.Models[0].Digest != "abcdef0123" {
+		t.Errorf("Expected lowercase digest 'abcdef0123', got '%s'", result.Models[0].Digest)
+	}
+
+	conv.PreserveDigestCase = true
+	result = conv.ConvertDMRToOllama(dmrModels)
+	if result.Models[0].Digest != "ABCDEF0123" {
+		t.Errorf("Expected digest 'ABCDEF0123', got '%s'", result.Models[0].Digest)
+	}
+}
+
+func TestSkipInvalid(t *testing.T) {
+	dmrModels := []DMRModel{
+		{ID: "sha256:test1", Tags: []string{"model1"}},
+		{ID: ""},
+		{ID: "sha256:test2"},
+		{ID: "sha256:"},
+		{Tags: []string{"model3"}},
+	}
+
+	// Kept by default
+	conv := NewConverter()
+	if result := conv.ConvertDMRToOllama(dmrModels); len(result.Models) != 5 {
+		t.Errorf("Expected all 5 models without SkipInvalid, got %d", len(result.Models))
+	}
+
+	var logs bytes.Buffer
+	conv.SkipInvalid = true
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	result := conv.ConvertDMRToOllama(dmrModels)
+	var names []string
+	for _, model := range result.Models {
+		names = append(names, model.Name)
+	}
+	if strings.Join(names, ",") != "model1,test2,model3" {
+		t.Errorf("Expected models model1,test2,model3, got %v", names)
+	}
+
+	if count := strings.Count(logs.String(), "skipping DMR model"); count != 2 {
+		t.Errorf("Expected 2 skip warnings, got %d in %q", count, logs.String())
+	}
+}
+
+func TestConvertFromURLsStableOutput(t *testing.T) {
+	// Later servers answer first, and each returns several tags per model
+	var urls []string
+	for i := 0; i < 3; i++ {
+		delay := time.Duration(3-i) * 10 * time.Millisecond
+		body := fmt.Sprintf(`[
+			{"id": "sha256:a%d", "tags": ["ai/model%d:8B", "ai/model%d:latest", "ai/model%d:Q4"], "created": 1745698622},
+			{"id": "sha256:b%d", "tags": ["ai/other%d:1B"], "created": 1745698622}
+		]`, i, i, i, i, i, i)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			w.Write([]byte(body))
+		}))
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+
+	conv := NewConverter()
+	conv.SynthesizeLatest = true
+
+	var first []byte
+	for run := 0; run < 5; run++ {
+		response, err := conv.ConvertFromURLs(context.Background(), urls)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		jsonData, _ := json.MarshalIndent(response, "", "  ")
+
+		if run == 0 {
+			first = jsonData
+			continue
+		}
+		if string(jsonData) != string(first) {
+			t.Fatalf("Expected run %d to be byte-identical to the first\nfirst:\n%s\nrun:\n%s", run, first, jsonData)
+		}
+	}
+
+	var response OllamaResponse
+	json.Unmarshal(first, &response)
+	var names []string
+	for _, model := range response.Models {
+		names = append(names, model.Name)
+	}
+	expected := "ai/model0:latest,ai/other0:1B,ai/model1:latest,ai/other1:1B,ai/model2:latest,ai/other2:1B,ai/other0:latest,ai/other1:latest,ai/other2:latest"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("Expected models in DMR and server order %s, got %s", expected, strings.Join(names, ","))
+	}
+}
+
+func TestStrictSize(t *testing.T) {
+	jsonData := []byte(`[
+		{"id": "sha256:test1", "tags": ["model1"], "config": {"size": "1 GiB"}},
+		{"id": "sha256:test2", "tags": ["model2"], "config": {"size": "1.2 GB"}},
+		{"id": "sha256:test3", "tags": ["model3"], "config": {"size": "0"}},
+		{"id": "sha256:test4", "tags": ["model4"], "config": {"size": ""}}
+	]`)
+
+	// Unparseable sizes are reported as 0 by default
+	conv := NewConverter()
+	response, err := conv.ConvertFromJSON(jsonData)
+	if err != nil || len(response.Models) != 4 || response.Models[1].Size != 0 {
+		t.Fatalf("Expected 4 models with model2 at size 0, got %v (%v)", response.Models, err)
+	}
+
+	conv.StrictSize = StrictSizeFail
+	_, err = conv.ConvertFromJSON(jsonData)
+	if !errors.Is(err, ErrParse) || !strings.Contains(err.Error(), `"1.2 GB"`) {
+		t.Errorf("Expected parse error for size '1.2 GB', got %v", err)
+	}
+
+	var streamed bytes.Buffer
+	models, _ := conv.ParseDMRModels(jsonData)
+	if err := conv.StreamConvert(&streamed, models); !errors.Is(err, ErrParse) {
+		t.Errorf("Expected streaming to fail with a parse error, got %v", err)
+	}
+
+	// ConvertDMRToOllama can't fail, so it keeps the model at size 0
+	response = conv.ConvertDMRToOllama(models)
+	if len(response.Models) != 4 || response.Models[1].Size != 0 {
+		t.Errorf("Expected 4 models with model2 at size 0, got %v", response.Models)
+	}
+
+	var logs bytes.Buffer
+	conv.StrictSize = StrictSizeSkip
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+	response, err = conv.ConvertFromJSON(jsonData)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(response.Models) != 3 || response.Models[1].Name != "model3" {
+		t.Errorf("Expected model2 to be skipped, got %v", response.Models)
+	}
+	if !strings.Contains(logs.String(), "unparseable size") {
+		t.Errorf("Expected skip warning, got %q", logs.String())
+	}
+}
+
+func TestDuplicateNames(t *testing.T) {
+	jsonData := []byte(`[
+		{"id": "sha256:aaa", "tags": ["ai/model1:latest"]},
+		{"id": "sha256:bbb", "tags": ["ai/model1:latest"]},
+		{"id": "sha256:aaa", "tags": ["ai/model1:latest"]}
+	]`)
+
+	var logs bytes.Buffer
+	conv := NewConverter()
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+	response, err := conv.ConvertFromJSON(jsonData)
+	if err != nil || len(response.Models) != 3 {
+		t.Fatalf("Expected 3 models and only a warning, got %d (%v)", len(response.Models), err)
+	}
+
+	// The repeated sha256:aaa entry is the same model, so it warns only once
+	if strings.Count(logs.String(), "share a name") != 1 {
+		t.Errorf("Expected one duplicate name warning, got logs: %s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "digest=aaa") || !strings.Contains(logs.String(), "conflicting_digest=bbb") {
+		t.Errorf("Expected the warning to name both digests, got logs: %s", logs.String())
+	}
+
+	conv.StrictDuplicates = true
+	_, err = conv.ConvertFromJSON(jsonData)
+	if !errors.Is(err, ErrDuplicateName) || !strings.Contains(err.Error(), "ai/model1:latest") {
+		t.Errorf("Expected duplicate name error for ai/model1:latest, got %v", err)
+	}
+
+	models, _ := conv.ParseDMRModels(jsonData)
+	if err := conv.StreamConvert(&bytes.Buffer{}, models); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("Expected streaming to fail with a duplicate name error, got %v", err)
+	}
+
+	// ConvertDMRToOllama can't fail, so it keeps every model
+	if response := conv.ConvertDMRToOllama(models); len(response.Models) != 3 {
+		t.Errorf("Expected 3 models, got %d", len(response.Models))
+	}
+}
@@ -0,0 +1,25 @@
+package converter
+
+// ModelDetailsSummary is the compact per-model view for monitoring tools
+// that only need a model's details, not the full Ollama envelope
+type ModelDetailsSummary struct {
+	Name              string `json:"name"`
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+// SummarizeDetails returns the details summary of each model in response,
+// in order. The result is never nil, so it encodes as [] rather than null.
+func SummarizeDetails(response OllamaResponse) []ModelDetailsSummary {
+	summaries := make([]ModelDetailsSummary, 0, len(response.Models))
+	for _, model := range response.Models {
+		summaries = append(summaries, ModelDetailsSummary{
+			Name:              model.Name,
+			Family:            model.Details.Family,
+			ParameterSize:     model.Details.ParameterSize,
+			QuantizationLevel: model.Details.QuantizationLevel,
+		})
+	}
+	return summaries
+}
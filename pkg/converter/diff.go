@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ResponseDiff describes how the models in two Ollama responses differ
+type ResponseDiff struct {
+	Added   []OllamaModel  `json:"added"`
+	Removed []OllamaModel  `json:"removed"`
+	Changed []ModelChanges `json:"changed"`
+}
+
+// ModelChanges lists the fields that changed for a model present in both responses
+type ModelChanges struct {
+	Name    string        `json:"name"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// FieldChange is a single changed field with its old and new values
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Empty reports whether the diff found no differences
+func (d ResponseDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffResponses compares two Ollama responses by model name. Results are
+// sorted by name so the output is stable.
+func DiffResponses(before, after OllamaResponse) ResponseDiff {
+	oldModels := make(map[string]OllamaModel, len(before.Models))
+	for _, model := range before.Models {
+		oldModels[model.Name] = model
+	}
+	newModels := make(map[string]OllamaModel, len(after.Models))
+	for _, model := range after.Models {
+		newModels[model.Name] = model
+	}
+
+	diff := ResponseDiff{
+		Added:   []OllamaModel{},
+		Removed: []OllamaModel{},
+		Changed: []ModelChanges{},
+	}
+
+	for name, newModel := range newModels {
+		oldModel, ok := oldModels[name]
+		if !ok {
+			diff.Added = append(diff.Added, newModel)
+			continue
+		}
+		if changes := compareModels(oldModel, newModel); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, ModelChanges{Name: name, Changes: changes})
+		}
+	}
+	for name, oldModel := range oldModels {
+		if _, ok := newModels[name]; !ok {
+			diff.Removed = append(diff.Removed, oldModel)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Name < diff.Added[j].Name })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Name < diff.Removed[j].Name })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+
+	return diff
+}
+
+// compareModels returns the fields that differ between two versions of a model
+func compareModels(before, after OllamaModel) []FieldChange {
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"model", before.Model, after.Model},
+		{"digest", before.Digest, after.Digest},
+		{"size", fmt.Sprint(before.Size), fmt.Sprint(after.Size)},
+		{"format", before.Details.Format, after.Details.Format},
+		{"family", before.Details.Family, after.Details.Family},
+		{"parameter_size", before.Details.ParameterSize, after.Details.ParameterSize},
+		{"quantization_level", before.Details.QuantizationLevel, after.Details.QuantizationLevel},
+	}
+
+	var changes []FieldChange
+	for _, field := range fields {
+		if field.old != field.new {
+			changes = append(changes, FieldChange{Field: field.name, Old: field.old, New: field.new})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,54 @@
+package converter
+
+import "testing"
+
+func TestDiffResponses(t *testing.T) {
+	before := OllamaResponse{
+		Models: []OllamaModel{
+			{Name: "model1", Digest: "aaa", Details: OllamaDetails{QuantizationLevel: "F16"}},
+			{Name: "model2", Digest: "bbb", Details: OllamaDetails{QuantizationLevel: "Q4_0"}},
+		},
+	}
+	after := OllamaResponse{
+		Models: []OllamaModel{
+			{Name: "model1", Digest: "aaa", Details: OllamaDetails{QuantizationLevel: "Q8_0"}},
+			{Name: "model3", Digest: "ccc", Details: OllamaDetails{QuantizationLevel: "F16"}},
+		},
+	}
+
+	diff := DiffResponses(before, after)
+
+	if len(diff.Added) != 1 || diff.Added[0].Name != "model3" {
+		t.Errorf("Expected model3 to be added, got %v", diff.Added)
+	}
+
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "model2" {
+		t.Errorf("Expected model2 to be removed, got %v", diff.Removed)
+	}
+
+	if len(diff.Changed) != 1 || diff.Changed[0].Name != "model1" {
+		t.Fatalf("Expected model1 to be changed, got %v", diff.Changed)
+	}
+
+	changes := diff.Changed[0].Changes
+	if len(changes) != 1 || changes[0].Field != "quantization_level" || changes[0].Old != "F16" || changes[0].New != "Q8_0" {
+		t.Errorf("Expected quantization_level change F16 -> Q8_0, got %v", changes)
+	}
+
+	if diff.Empty() {
+		t.Error("Expected diff to report differences")
+	}
+}
+
+func TestDiffResponsesIdentical(t *testing.T) {
+	response := OllamaResponse{
+		Models: []OllamaModel{
+			{Name: "model1", Digest: "aaa"},
+		},
+	}
+
+	diff := DiffResponses(response, response)
+	if !diff.Empty() {
+		t.Errorf("Expected no differences, got %+v", diff)
+	}
+}
@@ -0,0 +1,30 @@
+package converter
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// duplicateNames records the digest first converted under each model name,
+// to catch DMR models with different digests that claim the same tag
+type duplicateNames map[string]string
+
+// checkDuplicate records model's name, warning when a model with another
+// digest already has it. With StrictDuplicates the conflict is returned as
+// an ErrDuplicateName error instead.
+func (c *Converter) checkDuplicate(seen duplicateNames, model OllamaModel, logger *slog.Logger) error {
+	digest, ok := seen[model.Name]
+	if !ok {
+		seen[model.Name] = model.Digest
+		return nil
+	}
+	if digest == model.Digest {
+		return nil
+	}
+
+	if c.StrictDuplicates {
+		return fmt.Errorf("%w: %s is claimed by digests %s and %s", ErrDuplicateName, model.Name, digest, model.Digest)
+	}
+	logger.Warn("DMR models with different digests share a name", "name", model.Name, "digest", digest, "conflicting_digest", model.Digest)
+	return nil
+}
@@ -0,0 +1,74 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrFetch reports that the DMR API could not be reached or read
+	ErrFetch = errors.New("failed to fetch from DMR API")
+
+	// ErrHTTPStatus reports that the DMR API answered with an unexpected
+	// status. Use errors.As with *HTTPStatusError to get the status code.
+	ErrHTTPStatus = errors.New("unexpected DMR API status")
+
+	// ErrParse reports that a DMR response was not valid DMR JSON
+	ErrParse = errors.New("failed to parse DMR JSON")
+
+	// ErrModelNotFound reports that DMR has no model with the requested name
+	ErrModelNotFound = errors.New("model not found")
+
+	// ErrDuplicateName reports that DMR models with different digests
+	// converted to the same name, with Converter.StrictDuplicates set
+	ErrDuplicateName = errors.New("duplicate model name")
+)
+
+// maxErrorBodySnippet limits how much of an error response body is kept
+const maxErrorBodySnippet = 512
+
+// HTTPStatusError is returned when the DMR API responds with a non-200 status
+type HTTPStatusError struct {
+	StatusCode int
+
+	// Body holds the start of the response body, which often explains the failure
+	Body string
+
+	// Location is the redirect target of a 3xx response that wasn't followed
+	Location string
+}
+
+func (e *HTTPStatusError) Error() string {
+	msg := fmt.Sprintf("DMR API returned status: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Location != "" {
+		msg += " (redirect to " + e.Location + ")"
+	}
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Is lets errors.Is(err, ErrHTTPStatus) match any HTTPStatusError
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrHTTPStatus
+}
+
+// newHTTPStatusError builds an HTTPStatusError with a trimmed snippet of the body
+func newHTTPStatusError(resp *http.Response) *HTTPStatusError {
+	// Read one extra byte to know whether the body was cut off
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySnippet+1))
+	body := string(snippet)
+	if len(snippet) > maxErrorBodySnippet {
+		body = string(snippet[:maxErrorBodySnippet]) + "..."
+	}
+
+	return &HTTPStatusError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(body),
+		Location:   resp.Header.Get("Location"),
+	}
+}
@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchDMRModelsHTTPStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	_, err := conv.FetchDMRModels(server.URL + "/models")
+
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected HTTPStatusError, got %v", err)
+	}
+
+	if statusErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status code 500, got %d", statusErr.StatusCode)
+	}
+
+	if !errors.Is(err, ErrHTTPStatus) {
+		t.Error("Expected errors.Is to match ErrHTTPStatus")
+	}
+}
+
+func TestFetchDMRModelsFetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	conv := NewConverter()
+	_, err := conv.FetchDMRModels(url)
+	if !errors.Is(err, ErrFetch) {
+		t.Errorf("Expected ErrFetch, got %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`not json`))
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	_, err := conv.FetchDMRModels(server.URL)
+	if !errors.Is(err, ErrParse) {
+		t.Errorf("Expected ErrParse from fetch, got %v", err)
+	}
+
+	_, err = conv.ConvertFromJSON([]byte(`not json`))
+	if !errors.Is(err, ErrParse) {
+		t.Errorf("Expected ErrParse from ConvertFromJSON, got %v", err)
+	}
+}
+
+func TestConvertFromURLsWrapsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	_, err := conv.ConvertFromURLs(t.Context(), []string{server.URL})
+
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected HTTPStatusError with 503 through aggregation, got %v", err)
+	}
+}
+
+func TestHTTPStatusErrorBodySnippet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("  model store is not initialized\n"))
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	_, err := conv.FetchDMRModels(server.URL)
+	if err == nil {
+		t.Fatal("Expected error for HTTP 500, got nil")
+	}
+
+	expected := "DMR API returned status: 500 Internal Server Error: model store is not initialized"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestHTTPStatusErrorLargeBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(strings.Repeat("x", 1<<20)))
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	_, err := conv.FetchDMRModels(server.URL)
+
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected HTTPStatusError, got %v", err)
+	}
+
+	if len(statusErr.Body) != maxErrorBodySnippet+len("...") {
+		t.Errorf("Expected body snippet to be truncated to %d bytes, got %d", maxErrorBodySnippet, len(statusErr.Body))
+	}
+}
@@ -0,0 +1,44 @@
+package converter
+
+import "context"
+
+// DMRFetcher is a source of DMR models. HTTPFetcher is the implementation
+// backed by the DMR API; tests and embedding services can supply their own
+// to convert models without a server.
+type DMRFetcher interface {
+	Fetch(ctx context.Context) ([]DMRModel, error)
+}
+
+// DMRFetcherFunc adapts an ordinary function to the DMRFetcher interface
+type DMRFetcherFunc func(ctx context.Context) ([]DMRModel, error)
+
+// Fetch calls f(ctx)
+func (f DMRFetcherFunc) Fetch(ctx context.Context) ([]DMRModel, error) {
+	return f(ctx)
+}
+
+// httpFetcher fetches models from one DMR endpoint through a converter
+type httpFetcher struct {
+	conv *Converter
+	url  string
+}
+
+func (f httpFetcher) Fetch(ctx context.Context) ([]DMRModel, error) {
+	return f.conv.FetchDMRModelsContext(ctx, f.url)
+}
+
+// HTTPFetcher returns a DMRFetcher that reads url with the converter's HTTP
+// client, including conditional requests and pagination
+func (c *Converter) HTTPFetcher(url string) DMRFetcher {
+	return httpFetcher{conv: c, url: url}
+}
+
+// ConvertFrom fetches DMR models from fetcher and converts them to Ollama format
+func (c *Converter) ConvertFrom(ctx context.Context, fetcher DMRFetcher) (OllamaResponse, error) {
+	dmrModels, err := fetcher.Fetch(ctx)
+	if err != nil {
+		return OllamaResponse{}, err
+	}
+
+	return c.ConvertDMRToOllamaContext(ctx, dmrModels)
+}
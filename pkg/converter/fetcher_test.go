@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeFetcher serves a fixed list of models from memory
+type fakeFetcher struct {
+	models []DMRModel
+	err    error
+	calls  int
+}
+
+func (f *fakeFetcher) Fetch(ctx context.Context) ([]DMRModel, error) {
+	f.calls++
+	return f.models, f.err
+}
+
+func TestConvertFromFakeFetcher(t *testing.T) {
+	fetcher := &fakeFetcher{models: []DMRModel{
+		{
+			ID:     "sha256:test1",
+			Tags:   []string{"model1"},
+			Config: DMRConfig{Architecture: "llama", Size: "1GB"},
+		},
+	}}
+
+	conv := NewConverter()
+	result, err := conv.ConvertFrom(context.Background(), fetcher)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if fetcher.calls != 1 {
+		t.Errorf("Expected 1 fetch, got %d", fetcher.calls)
+	}
+
+	if len(result.Models) != 1 || result.Models[0].Name != "model1" {
+		t.Fatalf("Expected model 'model1', got %+v", result.Models)
+	}
+
+	if result.Models[0].Details.Family != "llama" {
+		t.Errorf("Expected family 'llama', got '%s'", result.Models[0].Details.Family)
+	}
+
+	// Fetch errors are returned unchanged
+	fetchErr := errors.New("source unavailable")
+	_, err = conv.ConvertFrom(context.Background(), DMRFetcherFunc(func(ctx context.Context) ([]DMRModel, error) {
+		return nil, fetchErr
+	}))
+	if !errors.Is(err, fetchErr) {
+		t.Errorf("Expected fetch error, got %v", err)
+	}
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	result, err := conv.ConvertFrom(context.Background(), conv.HTTPFetcher(server.URL))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.Models) != 1 || result.Models[0].Name != "model1" {
+		t.Errorf("Expected model 'model1', got %+v", result.Models)
+	}
+}
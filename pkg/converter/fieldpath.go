@@ -0,0 +1,136 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// applyFieldPaths fills config from raw using JSONPath-like paths such as
+// "$.descriptor.params" or "$.layers[0].size". Paths that don't resolve
+// leave the field as decoded from the standard schema.
+func applyFieldPaths(config *DMRConfig, raw map[string]any, paths map[string]string) error {
+	for field, path := range paths {
+		target, err := configField(config, field)
+		if err != nil {
+			return err
+		}
+
+		value, ok, err := resolvePath(raw, path)
+		if err != nil {
+			return err
+		}
+		if ok {
+			*target = value
+		}
+	}
+	return nil
+}
+
+// ValidateFieldPaths checks that each field is a known DMRConfig field and
+// each path is well formed, without needing a DMR response to resolve it
+func ValidateFieldPaths(paths map[string]string) error {
+	for field, path := range paths {
+		_, err := configField(&DMRConfig{}, field)
+		if err != nil {
+			return err
+		}
+
+		if path != "$" && !strings.HasPrefix(path, "$.") {
+			return fmt.Errorf("invalid field path %q: must start with \"$.\"", path)
+		}
+		for _, segment := range strings.Split(strings.TrimPrefix(path, "$"), ".")[1:] {
+			_, _, err := splitPathSegment(segment)
+			if err != nil {
+				return fmt.Errorf("invalid field path %q: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// configField returns a pointer to the DMRConfig field with the given JSON name
+func configField(config *DMRConfig, field string) (*string, error) {
+	switch field {
+	case "format":
+		return &config.Format, nil
+	case "quantization":
+		return &config.Quantization, nil
+	case "parameters":
+		return &config.Parameters, nil
+	case "architecture":
+		return &config.Architecture, nil
+	case "size":
+		return &config.Size, nil
+	default:
+		return nil, fmt.Errorf("unknown config field %q", field)
+	}
+}
+
+// resolvePath walks raw along a "$."-rooted path of object keys and [n]
+// array indexes, returning the value found as a string
+func resolvePath(raw map[string]any, path string) (string, bool, error) {
+	if path != "$" && !strings.HasPrefix(path, "$.") {
+		return "", false, fmt.Errorf("invalid field path %q: must start with \"$.\"", path)
+	}
+
+	var current any = raw
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "$"), ".")[1:] {
+		key, indexes, err := splitPathSegment(segment)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid field path %q: %w", path, err)
+		}
+
+		if key != "" {
+			object, ok := current.(map[string]any)
+			if !ok {
+				return "", false, nil
+			}
+			current, ok = object[key]
+			if !ok {
+				return "", false, nil
+			}
+		}
+
+		for _, index := range indexes {
+			array, ok := current.([]any)
+			if !ok || index >= len(array) {
+				return "", false, nil
+			}
+			current = array[index]
+		}
+	}
+
+	switch value := current.(type) {
+	case string:
+		return value, true, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true, nil
+	case bool:
+		return strconv.FormatBool(value), true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+// splitPathSegment splits a segment like "layers[0]" into its key and indexes
+func splitPathSegment(segment string) (string, []int, error) {
+	key, rest, _ := strings.Cut(segment, "[")
+	if rest == "" {
+		return key, nil, nil
+	}
+
+	var indexes []int
+	for _, part := range strings.Split("["+rest, "[")[1:] {
+		digits, ok := strings.CutSuffix(part, "]")
+		if !ok {
+			return "", nil, fmt.Errorf("unterminated index in %q", segment)
+		}
+		index, err := strconv.Atoi(digits)
+		if err != nil || index < 0 {
+			return "", nil, fmt.Errorf("invalid index in %q", segment)
+		}
+		indexes = append(indexes, index)
+	}
+	return key, indexes, nil
+}
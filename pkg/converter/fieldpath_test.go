@@ -0,0 +1,92 @@
+package converter
+
+import "testing"
+
+func TestConvertFromJSONFieldPaths(t *testing.T) {
+	jsonData := []byte(`[
+		{
+			"id": "sha256:test1",
+			"tags": ["model1"],
+			"created": 1745698622,
+			"config": {"format": "gguf", "architecture": "llama"},
+			"descriptor": {
+				"params": "8B",
+				"quant": {"level": "Q4_K_M"},
+				"files": [{"bytes": 1073741824}]
+			}
+		}
+	]`)
+
+	conv := NewConverter()
+	conv.FieldPaths = map[string]string{
+		"parameters":   "$.descriptor.params",
+		"quantization": "$.descriptor.quant.level",
+		"size":         "$.descriptor.files[0].bytes",
+		"format":       "$.descriptor.missing",
+	}
+
+	response, err := conv.ConvertFromJSON(jsonData)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	details := response.Models[0].Details
+	if details.ParameterSize != "8B" {
+		t.Errorf("Expected parameter size '8B', got '%s'", details.ParameterSize)
+	}
+	if details.QuantizationLevel != "Q4_K_M" {
+		t.Errorf("Expected quantization 'Q4_K_M', got '%s'", details.QuantizationLevel)
+	}
+	if response.Models[0].Size != 1073741824 {
+		t.Errorf("Expected size 1073741824, got %d", response.Models[0].Size)
+	}
+
+	// Unresolved paths keep the standard config value
+	if details.Format != "gguf" {
+		t.Errorf("Expected format 'gguf' from config, got '%s'", details.Format)
+	}
+	if details.Family != "llama" {
+		t.Errorf("Expected family 'llama' from config, got '%s'", details.Family)
+	}
+}
+
+func TestConvertFromJSONInvalidFieldPaths(t *testing.T) {
+	jsonData := []byte(`[{"id": "sha256:test1"}]`)
+
+	tests := map[string]map[string]string{
+		"unknown field":    {"colour": "$.config.colour"},
+		"missing root":     {"parameters": "config.parameters"},
+		"bad array index":  {"parameters": "$.files[x]"},
+		"unterminated idx": {"parameters": "$.files[0"},
+	}
+
+	for name, paths := range tests {
+		t.Run(name, func(t *testing.T) {
+			conv := NewConverter()
+			conv.FieldPaths = paths
+			_, err := conv.ConvertFromJSON(jsonData)
+			if err == nil {
+				t.Error("Expected error for invalid field path, got nil")
+			}
+		})
+	}
+}
+
+func TestValidateFieldPaths(t *testing.T) {
+	valid := map[string]string{"parameters": "$.descriptor.params", "size": "$.layers[0].size"}
+	if err := ValidateFieldPaths(valid); err != nil {
+		t.Errorf("Expected valid field paths, got %v", err)
+	}
+
+	invalid := []map[string]string{
+		{"colour": "$.descriptor.colour"},
+		{"parameters": "descriptor.params"},
+		{"size": "$.layers[x].size"},
+		{"size": "$.layers[0.size"},
+	}
+	for _, paths := range invalid {
+		if err := ValidateFieldPaths(paths); err == nil {
+			t.Errorf("Expected error for %v, got nil", paths)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package converter
+
+import "strings"
+
+// splitNameTag splits a model name like "ai/llama3:8b" into its base name and
+// tag. Names without a tag, which Ollama treats as :latest, return "" as tag.
+// A colon before the last "/" belongs to a registry port, not a tag.
+func splitNameTag(name string) (base, tag string) {
+	i := strings.LastIndex(name, ":")
+	if i < 0 || i < strings.LastIndex(name, "/") {
+		return name, ""
+	}
+	return name[:i], name[i+1:]
+}
+
+// latestAliases collects the first variant of each base name, and whether
+// the base already has a :latest tag, to synthesize the missing aliases
+type latestAliases struct {
+	bases     []string
+	first     map[string]OllamaModel
+	hasLatest map[string]bool
+}
+
+func newLatestAliases() *latestAliases {
+	return &latestAliases{
+		first:     make(map[string]OllamaModel),
+		hasLatest: make(map[string]bool),
+	}
+}
+
+// add records a converted model
+func (a *latestAliases) add(model OllamaModel) {
+	base, tag := splitNameTag(model.Name)
+	if tag == "" || tag == "latest" {
+		a.hasLatest[base] = true
+		return
+	}
+	if _, ok := a.first[base]; !ok {
+		a.first[base] = model
+		a.bases = append(a.bases, base)
+	}
+}
+
+// models returns a "<base>:latest" copy of the first variant of each base
+// name that has no :latest tag, in the order the bases were first seen
+func (a *latestAliases) models() []OllamaModel {
+	var aliases []OllamaModel
+	for _, base := range a.bases {
+		if a.hasLatest[base] {
+			continue
+		}
+		alias := a.first[base]
+		alias.Name = base + ":latest"
+		aliases = append(aliases, alias)
+	}
+	return aliases
+}
@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// latestTestModels has llama3 without a :latest tag and qwen3 with one
+var latestTestModels = []DMRModel{
+	{ID: "sha256:aaa", Tags: []string{"ai/llama3:8B"}},
+	{ID: "sha256:bbb", Tags: []string{"ai/llama3:70B"}},
+	{ID: "sha256:ccc", Tags: []string{"ai/qwen3:latest", "ai/qwen3:4B"}},
+	{ID: "sha256:ddd", Tags: []string{"ai/qwen3:8B"}},
+}
+
+func TestSplitNameTag(t *testing.T) {
+	tests := map[string][2]string{
+		"ai/llama3:8B":            {"ai/llama3", "8B"},
+		"llama3":                  {"llama3", ""},
+		"localhost:5000/llama3":   {"localhost:5000/llama3", ""},
+		"localhost:5000/llama3:1": {"localhost:5000/llama3", "1"},
+	}
+
+	for name, expected := range tests {
+		base, tag := splitNameTag(name)
+		if base != expected[0] || tag != expected[1] {
+			t.Errorf("splitNameTag(%q) = (%q, %q), expected (%q, %q)", name, base, tag, expected[0], expected[1])
+		}
+	}
+}
+
+func TestSynthesizeLatest(t *testing.T) {
+	conv := NewConverter()
+	if result := conv.ConvertDMRToOllama(latestTestModels); len(result.Models) != 4 {
+		t.Errorf("Expected no aliases by default, got %d models", len(result.Models))
+	}
+
+	conv.SynthesizeLatest = true
+	result := conv.ConvertDMRToOllama(latestTestModels)
+	if len(result.Models) != 5 {
+		t.Fatalf("Expected one synthesized alias, got %d models", len(result.Models))
+	}
+
+	alias := result.Models[4]
+	if alias.Name != "ai/llama3:latest" || alias.Model != "ai/llama3:8B" || alias.Digest != "aaa" {
+		t.Errorf("Expected ai/llama3:latest pointing at ai/llama3:8B, got %s -> %s (%s)", alias.Name, alias.Model, alias.Digest)
+	}
+
+	// Streaming produces the same aliases
+	expected, _ := json.MarshalIndent(result, "", "  ")
+	var streamed bytes.Buffer
+	if err := conv.StreamConvert(&streamed, latestTestModels); err != nil {
+		t.Fatalf("Expected no error streaming, got %v", err)
+	}
+	if streamed.String() != string(expected) {
+		t.Errorf("Expected streamed output to match buffered output\nstreamed:\n%s\nbuffered:\n%s", streamed.String(), expected)
+	}
+}
+
+func TestSynthesizeLatestMaxModels(t *testing.T) {
+	conv := NewConverter()
+	conv.SynthesizeLatest = true
+
+	// The cap leaves no room for the alias
+	conv.MaxModels = 1
+	result := conv.ConvertDMRToOllama(latestTestModels[:1])
+	if len(result.Models) != 1 || result.Models[0].Name != "ai/llama3:8B" {
+		t.Errorf("Expected only ai/llama3:8B within the cap, got %+v", result.Models)
+	}
+
+	// The alias fills the last free slot
+	conv.MaxModels = 5
+	result = conv.ConvertDMRToOllama(latestTestModels)
+	if len(result.Models) != 5 || result.Models[4].Name != "ai/llama3:latest" {
+		t.Errorf("Expected the alias as the fifth model, got %+v", result.Models)
+	}
+
+	conv.MaxModels = 1
+	expected, _ := json.MarshalIndent(conv.ConvertDMRToOllama(latestTestModels[:1]), "", "  ")
+	var streamed bytes.Buffer
+	if err := conv.StreamConvert(&streamed, latestTestModels[:1]); err != nil {
+		t.Fatalf("Expected no error streaming, got %v", err)
+	}
+	if streamed.String() != string(expected) {
+		t.Errorf("Expected streamed output to respect the cap\nstreamed:\n%s\nbuffered:\n%s", streamed.String(), expected)
+	}
+}
+
+func TestFindDMRModelSynthesizedLatest(t *testing.T) {
+	conv := NewConverter()
+	if _, ok := conv.FindDMRModel(latestTestModels, "ai/llama3:latest"); ok {
+		t.Error("Expected ai/llama3:latest not to resolve without SynthesizeLatest")
+	}
+
+	conv.SynthesizeLatest = true
+	model, ok := conv.FindDMRModel(latestTestModels, "ai/llama3:latest")
+	if !ok || model.ID != "sha256:aaa" {
+		t.Errorf("Expected ai/llama3:latest to resolve to the first variant, got %v", model.ID)
+	}
+
+	// Real :latest tags still win
+	model, ok = conv.FindDMRModel(latestTestModels, "ai/qwen3:latest")
+	if !ok || model.ID != "sha256:ccc" {
+		t.Errorf("Expected ai/qwen3:latest to resolve to its tagged model, got %v", model.ID)
+	}
+}
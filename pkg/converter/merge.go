@@ -0,0 +1,97 @@
+package converter
+
+import "time"
+
+// MergeResponses combines several Ollama responses into one, deduplicating
+// models by digest. When the same digest appears more than once, the first
+// entry is kept, the other names are merged into its Tags, and the most
+// recent ModifiedAt wins. Models keep the order they were first seen in.
+func MergeResponses(responses ...OllamaResponse) OllamaResponse {
+	var merged []OllamaModel
+	index := make(map[string]int)
+
+	for _, response := range responses {
+		for _, model := range response.Models {
+			i, ok := index[model.Digest]
+			if !ok {
+				index[model.Digest] = len(merged)
+				merged = append(merged, model)
+				continue
+			}
+			merged[i] = mergeModel(merged[i], model)
+		}
+	}
+
+	return OllamaResponse{Models: merged}
+}
+
+// MergeDMRModels deduplicates DMR models by ID, for model lists read from
+// several captures. When an ID appears more than once, the first entry is
+// kept, the other entries' tags are added to its Tags, and the latest
+// Created wins. Models keep the order they were first seen in, and models
+// without an ID are never merged.
+func MergeDMRModels(dmrModels []DMRModel) []DMRModel {
+	merged := make([]DMRModel, 0, len(dmrModels))
+	index := make(map[string]int)
+
+	for _, dmrModel := range dmrModels {
+		i, ok := index[dmrModel.ID]
+		if !ok || dmrModel.ID == "" {
+			index[dmrModel.ID] = len(merged)
+			merged = append(merged, dmrModel)
+			continue
+		}
+
+		existing := &merged[i]
+		seen := make(map[string]bool)
+		var tags []string
+		for _, modelTags := range [][]string{existing.Tags, dmrModel.Tags} {
+			for _, tag := range modelTags {
+				if !seen[tag] {
+					seen[tag] = true
+					tags = append(tags, tag)
+				}
+			}
+		}
+		existing.Tags = tags
+		existing.Created = max(existing.Created, dmrModel.Created)
+	}
+
+	return merged
+}
+
+// mergeModel folds a duplicate entry for the same digest into an existing model
+func mergeModel(existing, duplicate OllamaModel) OllamaModel {
+	seen := map[string]bool{existing.Name: true}
+	var tags []string
+	for _, tag := range existing.Tags {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range append([]string{duplicate.Name}, duplicate.Tags...) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	existing.Tags = tags
+
+	if newerTimestamp(duplicate.ModifiedAt, existing.ModifiedAt) {
+		existing.ModifiedAt = duplicate.ModifiedAt
+	}
+
+	return existing
+}
+
+// newerTimestamp reports whether RFC3339 timestamp a is later than b
+func newerTimestamp(a, b string) bool {
+	timeA, errA := time.Parse(time.RFC3339, a)
+	timeB, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		// Fall back to comparing the strings, which sorts RFC3339 in the same zone
+		return a > b
+	}
+	return timeA.After(timeB)
+}
@@ -0,0 +1,64 @@
+package converter
+
+import "testing"
+
+func TestMergeResponses(t *testing.T) {
+	first := OllamaResponse{
+		Models: []OllamaModel{
+			{Name: "model1", Digest: "aaa", ModifiedAt: "2025-01-01T00:00:00Z"},
+			{Name: "model2", Digest: "bbb", ModifiedAt: "2025-01-01T00:00:00Z"},
+		},
+	}
+	second := OllamaResponse{
+		Models: []OllamaModel{
+			{Name: "model1-alias", Digest: "aaa", ModifiedAt: "2025-02-01T00:00:00Z"},
+			{Name: "model3", Digest: "ccc", ModifiedAt: "2025-01-01T00:00:00Z"},
+		},
+	}
+
+	merged := MergeResponses(first, second)
+
+	if len(merged.Models) != 3 {
+		t.Fatalf("Expected 3 models after dedup, got %d", len(merged.Models))
+	}
+
+	model := merged.Models[0]
+	if model.Name != "model1" {
+		t.Errorf("Expected first seen name 'model1' to be kept, got '%s'", model.Name)
+	}
+
+	if len(model.Tags) != 1 || model.Tags[0] != "model1-alias" {
+		t.Errorf("Expected merged tags [model1-alias], got %v", model.Tags)
+	}
+
+	if model.ModifiedAt != "2025-02-01T00:00:00Z" {
+		t.Errorf("Expected most recent modified_at, got '%s'", model.ModifiedAt)
+	}
+
+	if merged.Models[1].Name != "model2" || merged.Models[2].Name != "model3" {
+		t.Errorf("Expected order model1, model2, model3, got %s, %s", merged.Models[1].Name, merged.Models[2].Name)
+	}
+}
+
+func TestMergeDMRModels(t *testing.T) {
+	merged := MergeDMRModels([]DMRModel{
+		{ID: "sha256:aaa", Tags: []string{"model1"}, Created: 100},
+		{ID: "sha256:bbb", Tags: []string{"model2"}, Created: 100},
+		{ID: "sha256:aaa", Tags: []string{"model1", "model1-alias"}, Created: 200},
+		{Tags: []string{"untagged1"}},
+		{Tags: []string{"untagged2"}},
+	})
+
+	if len(merged) != 4 {
+		t.Fatalf("Expected 4 models after dedup, got %d", len(merged))
+	}
+	if len(merged[0].Tags) != 2 || merged[0].Tags[1] != "model1-alias" {
+		t.Errorf("Expected tags [model1 model1-alias], got %v", merged[0].Tags)
+	}
+	if merged[0].Created != 200 {
+		t.Errorf("Expected the latest created time 200, got %d", merged[0].Created)
+	}
+	if merged[1].ID != "sha256:bbb" {
+		t.Errorf("Expected first-seen order, got %s second", merged[1].ID)
+	}
+}
@@ -0,0 +1,56 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// DefaultNameTemplate keeps each model's converted name: its first
+// ":latest" tag, else its first tag, or its digest when it has no tags
+const DefaultNameTemplate = "{{.Name}}"
+
+// NameTemplateData is what a name template is executed with
+type NameTemplateData struct {
+	// Name is the converted name, before the template is applied
+	Name              string
+	Family            string
+	ParameterSize     string
+	QuantizationLevel string
+	Format            string
+	Digest            string
+}
+
+// ParseNameTemplate parses a text/template for Converter.NameTemplate, e.g.
+// "{{.Family}}:{{.ParameterSize}}-{{.QuantizationLevel}}". The template is
+// also executed once with empty NameTemplateData, so unknown fields and
+// other mistakes are reported here rather than for every model.
+func ParseNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.Execute(io.Discard, NameTemplateData{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// executeNameTemplate returns tmpl executed with model's NameTemplateData
+func executeNameTemplate(tmpl *template.Template, model OllamaModel) (string, error) {
+	var b strings.Builder
+	err := tmpl.Execute(&b, NameTemplateData{
+		Name:              model.Name,
+		Family:            model.Details.Family,
+		ParameterSize:     model.Details.ParameterSize,
+		QuantizationLevel: model.Details.QuantizationLevel,
+		Format:            model.Details.Format,
+		Digest:            model.Digest,
+	})
+	if err != nil {
+		return "", fmt.Errorf("name template failed for %s: %w", model.Name, err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNameTemplate(t *testing.T) {
+	dmrModel := DMRModel{
+		ID:   "sha256:ABC123",
+		Tags: []string{"ai/llama3.2:3B-Q4_K_M"},
+		Config: DMRConfig{
+			Architecture: "llama",
+			Parameters:   "3.21 B",
+			Quantization: "Q4_K_M",
+		},
+	}
+
+	tests := map[string]string{
+		DefaultNameTemplate: "ai/llama3.2:3B-Q4_K_M",
+		"{{.Family}}:{{.ParameterSize}}-{{.QuantizationLevel}}": "llama:3.21 B-Q4_K_M",
+		"{{.Family}}@{{.Digest}}":                               "llama@abc123",
+	}
+
+	for text, expected := range tests {
+		tmpl, err := ParseNameTemplate(text)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", text, err)
+		}
+
+		conv := NewConverter()
+		conv.NameTemplate = tmpl
+		result := conv.ConvertDMRToOllama([]DMRModel{dmrModel})
+		if result.Models[0].Name != expected || result.Models[0].Model != expected {
+			t.Errorf("%s: expected name and model '%s', got '%s' and '%s'", text, expected, result.Models[0].Name, result.Models[0].Model)
+		}
+	}
+
+	if _, err := ParseNameTemplate("{{.Family"); err == nil {
+		t.Error("Expected error for malformed template, got nil")
+	}
+	if _, err := ParseNameTemplate("{{.Familly}}"); err == nil {
+		t.Error("Expected error for unknown field, got nil")
+	}
+}
+
+func TestNameTemplateError(t *testing.T) {
+	// Slicing past the end only fails for short names, not at parse time
+	tmpl, err := ParseNameTemplate(`{{if .Name}}{{slice .Name 0 10}}{{end}}`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	conv := NewConverter()
+	conv.NameTemplate = tmpl
+	_, err = conv.ConvertDMRToOllamaContext(context.Background(), []DMRModel{{ID: "sha256:aaa", Tags: []string{"ai/m"}}})
+	if err == nil {
+		t.Error("Expected the template error for the model, got nil")
+	}
+
+	// ConvertDMRToOllama can't fail, so the model keeps its name
+	response := conv.ConvertDMRToOllama([]DMRModel{{ID: "sha256:aaa", Tags: []string{"ai/m"}}})
+	if len(response.Models) != 1 || response.Models[0].Name != "ai/m" {
+		t.Errorf("Expected model 'ai/m' to keep its name, got %v", response.Models)
+	}
+}
@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parameterUnits are the parameter count suffixes, smallest first
+var parameterUnits = []string{"K", "M", "B", "T"}
+
+// NormalizeParameterSize canonicalizes a DMR parameter count like "7b",
+// "361.82 M" or "1500M" to Ollama's form: no space, an uppercase unit, and
+// the largest unit that keeps the number at least 1 ("1500M" becomes
+// "1.5B"). Values it can't parse are returned trimmed but otherwise as-is.
+func NormalizeParameterSize(size string) string {
+	size = strings.TrimSpace(size)
+	if size == "" {
+		return size
+	}
+
+	compact := strings.ToUpper(strings.ReplaceAll(size, " ", ""))
+	unit := -1
+	for i, candidate := range parameterUnits {
+		if strings.HasSuffix(compact, candidate) {
+			unit = i
+			break
+		}
+	}
+	if unit < 0 {
+		return size
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSuffix(compact, parameterUnits[unit]), 64)
+	if err != nil || value < 0 {
+		return size
+	}
+
+	for value >= 1000 && unit < len(parameterUnits)-1 {
+		value /= 1000
+		unit++
+	}
+
+	return strconv.FormatFloat(value, 'f', -1, 64) + parameterUnits[unit]
+}
+
+// quantizationLevels maps uppercased GGUF quantization names and their
+// unambiguous short forms to the name Ollama reports
+var quantizationLevels = map[string]string{
+	"F32":     "F32",
+	"FP32":    "F32",
+	"F16":     "F16",
+	"FP16":    "F16",
+	"BF16":    "BF16",
+	"Q4_0":    "Q4_0",
+	"Q4_1":    "Q4_1",
+	"Q5_0":    "Q5_0",
+	"Q5_1":    "Q5_1",
+	"Q8_0":    "Q8_0",
+	"Q2_K":    "Q2_K",
+	"Q3_K":    "Q3_K_M",
+	"Q3_K_S":  "Q3_K_S",
+	"Q3_K_M":  "Q3_K_M",
+	"Q3_K_L":  "Q3_K_L",
+	"Q4_K":    "Q4_K_M",
+	"Q4_K_S":  "Q4_K_S",
+	"Q4_K_M":  "Q4_K_M",
+	"Q5_K":    "Q5_K_M",
+	"Q5_K_S":  "Q5_K_S",
+	"Q5_K_M":  "Q5_K_M",
+	"Q6_K":    "Q6_K",
+	"IQ1_S":   "IQ1_S",
+	"IQ1_M":   "IQ1_M",
+	"IQ2_XXS": "IQ2_XXS",
+	"IQ2_XS":  "IQ2_XS",
+	"IQ2_S":   "IQ2_S",
+	"IQ2_M":   "IQ2_M",
+	"IQ3_XXS": "IQ3_XXS",
+	"IQ3_XS":  "IQ3_XS",
+	"IQ3_S":   "IQ3_S",
+	"IQ3_M":   "IQ3_M",
+	"IQ4_NL":  "IQ4_NL",
+	"IQ4_XS":  "IQ4_XS",
+	"MXFP4":   "MXFP4",
+}
+
+// NormalizeQuantization canonicalizes a DMR quantization level to Ollama's
+// naming: known GGUF types are uppercased ("q4_k_m" becomes "Q4_K_M") and
+// unambiguous short forms are expanded the way llama.cpp resolves them
+// ("Q4_K" becomes "Q4_K_M", "FP16" becomes "F16"). Ambiguous forms like
+// "Q4" and unknown values are returned as-is.
+func NormalizeQuantization(quantization string) string {
+	if level, ok := quantizationLevels[strings.ToUpper(strings.TrimSpace(quantization))]; ok {
+		return level
+	}
+	return quantization
+}
@@ -0,0 +1,71 @@
+package converter
+
+import "testing"
+
+func TestNormalizeParameterSize(t *testing.T) {
+	tests := map[string]string{
+		"1B":       "1B",
+		"7b":       "7B",
+		"1.5B":     "1.5B",
+		"1500M":    "1.5B",
+		"361.82 M": "361.82M",
+		"8.03 B":   "8.03B",
+		"137m":     "137M",
+		"":         "",
+		"unknown":  "unknown",
+		"1.2.3B":   "1.2.3B",
+	}
+
+	for input, expected := range tests {
+		if result := NormalizeParameterSize(input); result != expected {
+			t.Errorf("NormalizeParameterSize(%q) = '%s', expected '%s'", input, result, expected)
+		}
+	}
+}
+
+func TestConvertNormalizesParameterSize(t *testing.T) {
+	dmrModel := DMRModel{ID: "sha256:test1", Tags: []string{"model1"}, Config: DMRConfig{Parameters: "1500 M"}}
+
+	conv := NewConverter()
+	if result := conv.convertSingleModel(dmrModel); result.Details.ParameterSize != "1500 M" {
+		t.Errorf("Expected parameter size unchanged by default, got '%s'", result.Details.ParameterSize)
+	}
+
+	conv.NormalizeParameterSizes = true
+	if result := conv.convertSingleModel(dmrModel); result.Details.ParameterSize != "1.5B" {
+		t.Errorf("Expected normalized parameter size '1.5B', got '%s'", result.Details.ParameterSize)
+	}
+}
+
+func TestNormalizeQuantization(t *testing.T) {
+	tests := map[string]string{
+		"F16":         "F16",
+		"fp16":        "F16",
+		"q4_k_m":      "Q4_K_M",
+		"Q4_K":        "Q4_K_M",
+		"iq4_xs":      "IQ4_XS",
+		"Q4":          "Q4",
+		"custom-int3": "custom-int3",
+		"":            "",
+	}
+
+	for input, expected := range tests {
+		if result := NormalizeQuantization(input); result != expected {
+			t.Errorf("NormalizeQuantization(%q) = '%s', expected '%s'", input, result, expected)
+		}
+	}
+}
+
+func TestConvertNormalizesQuantization(t *testing.T) {
+	dmrModel := DMRModel{ID: "sha256:test1", Tags: []string{"model1"}, Config: DMRConfig{Quantization: "q4_k_m"}}
+
+	conv := NewConverter()
+	if result := conv.convertSingleModel(dmrModel); result.Details.QuantizationLevel != "q4_k_m" {
+		t.Errorf("Expected quantization unchanged by default, got '%s'", result.Details.QuantizationLevel)
+	}
+
+	conv.NormalizeQuantizations = true
+	if result := conv.convertSingleModel(dmrModel); result.Details.QuantizationLevel != "Q4_K_M" {
+		t.Errorf("Expected normalized quantization 'Q4_K_M', got '%s'", result.Details.QuantizationLevel)
+	}
+}
@@ -0,0 +1,53 @@
+package converter
+
+import "time"
+
+// OpenAIModelList is the OpenAI /v1/models response shape
+type OpenAIModelList struct {
+	Object string        `json:"object"`
+	Data   []OpenAIModel `json:"data"`
+}
+
+// OpenAIModel is a single entry in an OpenAI model list
+type OpenAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// OpenAIOwner is the owned_by value reported for converted models, the
+// same one DMR's own OpenAI-compatible endpoint uses
+const OpenAIOwner = "docker"
+
+// ConvertDMRToOpenAI converts DMR models to the OpenAI /v1/models shape,
+// applying the same naming and filtering as ConvertDMRToOllama
+func (c *Converter) ConvertDMRToOpenAI(dmrModels []DMRModel) OpenAIModelList {
+	return ConvertOllamaToOpenAI(c.ConvertDMRToOllama(dmrModels))
+}
+
+// ConvertOllamaToOpenAI converts an Ollama response to the OpenAI
+// /v1/models shape. Each model's name becomes its ID, and its modification
+// time, truncated to seconds, its creation time.
+func ConvertOllamaToOpenAI(response OllamaResponse) OpenAIModelList {
+	list := OpenAIModelList{
+		Object: "list",
+		Data:   make([]OpenAIModel, 0, len(response.Models)),
+	}
+
+	for _, model := range response.Models {
+		var created int64
+		if modifiedAt, err := time.Parse(time.RFC3339, model.ModifiedAt); err == nil {
+			created = modifiedAt.Unix()
+		}
+
+		list.Data = append(list.Data, OpenAIModel{
+			ID:      model.Name,
+			Object:  "model",
+			Created: created,
+			OwnedBy: OpenAIOwner,
+		})
+	}
+
+	return list
+}
@@ -0,0 +1,30 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvertDMRToOpenAI(t *testing.T) {
+	dmrModels := []DMRModel{
+		{ID: "sha256:test1", Tags: []string{"ai/model1:latest"}, Created: 1745698622},
+		{ID: "sha256:test2", Tags: []string{"ai/model2:8B"}, Created: 1745698623},
+	}
+
+	conv := NewConverter()
+	list := conv.ConvertDMRToOpenAI(dmrModels)
+
+	if list.Object != "list" || len(list.Data) != 2 {
+		t.Fatalf("Expected a list of 2 models, got %+v", list)
+	}
+
+	expected := OpenAIModel{ID: "ai/model1:latest", Object: "model", Created: 1745698622, OwnedBy: "docker"}
+	if list.Data[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, list.Data[0])
+	}
+
+	jsonData, _ := json.Marshal(ConvertOllamaToOpenAI(OllamaResponse{}))
+	if string(jsonData) != `{"object":"list","data":[]}` {
+		t.Errorf("Expected an empty data array, got %s", jsonData)
+	}
+}
@@ -0,0 +1,178 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultMaxPages is the number of pages FetchDMRModels follows for a
+// paginated DMR catalog when Converter.MaxPages is not set
+const DefaultMaxPages = 100
+
+// dmrPage is the envelope returned by DMR servers that paginate. Servers
+// that don't paginate return a bare array of models instead.
+type dmrPage struct {
+	Models     json.RawMessage `json:"models"`
+	Next       string          `json:"next"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+func (c *Converter) maxPages() int {
+	if c.MaxPages > 0 {
+		return c.MaxPages
+	}
+	return DefaultMaxPages
+}
+
+// parseDMRPage parses one page of DMR models fetched from pageURL and
+// returns the URL of the following page, or "" if this is the last one.
+// A Link header with rel="next" takes precedence over the body's "next"
+// URL, which takes precedence over a "next_cursor" token. Pages must match
+// the configured APIVersion.
+func (c *Converter) parseDMRPage(pageURL string, header http.Header, data []byte) ([]DMRModel, string, error) {
+	dmrModels, page, err := c.parseDMRBody(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := nextLink(header.Get("Link"))
+	if next == "" {
+		next = page.Next
+	}
+	if next == "" && page.NextCursor != "" {
+		next = withCursor(pageURL, page.NextCursor)
+	}
+
+	if next == "" {
+		return dmrModels, "", nil
+	}
+
+	nextURL, err := resolveURL(pageURL, next)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: invalid next page %q: %w", ErrParse, next, err)
+	}
+
+	return dmrModels, nextURL, nil
+}
+
+// parseDMRBody decodes a DMR response body, either a bare v0 array or a
+// v1 envelope, whose pagination fields it also returns
+func (c *Converter) parseDMRBody(data []byte) ([]DMRModel, dmrPage, error) {
+	var page dmrPage
+	trimmed := bytes.TrimSpace(data)
+	enveloped := len(trimmed) > 0 && trimmed[0] == '{'
+	if err := c.checkAPIVersion(enveloped); err != nil {
+		return nil, page, err
+	}
+
+	if enveloped {
+		if err := json.Unmarshal(trimmed, &page); err != nil {
+			return nil, page, fmt.Errorf("%w: %w", ErrParse, err)
+		}
+		data = page.Models
+	}
+
+	dmrModels, err := c.parseDMRModelArray(data)
+	return dmrModels, page, err
+}
+
+// fetchRemainingPages follows next links after the first page, stopping
+// with a warning once MaxPages pages have been read in total
+func (c *Converter) fetchRemainingPages(ctx context.Context, next string) ([]DMRModel, error) {
+	var dmrModels []DMRModel
+	for pages := 1; next != ""; pages++ {
+		if pages >= c.maxPages() {
+			c.contextLogger(ctx).Warn("stopping DMR pagination at page limit", "max_pages", c.maxPages(), "next", next)
+			break
+		}
+
+		header, body, err := c.fetchPage(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+
+		pageModels, following, err := c.parseDMRPage(next, header, body)
+		if err != nil {
+			return nil, err
+		}
+
+		dmrModels = append(dmrModels, pageModels...)
+		next = following
+	}
+
+	return dmrModels, nil
+}
+
+// fetchPage reads a single DMR page without conditional request headers
+func (c *Converter) fetchPage(ctx context.Context, pageURL string) (http.Header, []byte, error) {
+	req, err := c.newDMRRequest(ctx, pageURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, newHTTPStatusError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to read response body: %w", ErrFetch, err)
+	}
+	c.recordResponse(pageURL, body)
+
+	return resp.Header, body, nil
+}
+
+// nextLink extracts the rel="next" target from an RFC 8288 Link header
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, found := strings.Cut(link, ";")
+		if !found {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "rel") && strings.Trim(value, `"`) == "next" {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// withCursor returns pageURL with its cursor query parameter set to cursor
+func withCursor(pageURL, cursor string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	query := u.Query()
+	query.Set("cursor", cursor)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// resolveURL resolves ref, which may be relative, against base
+func resolveURL(base, ref string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
@@ -0,0 +1,105 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchDMRModelsFollowsLinkHeader(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`[{"id": "sha256:test2", "tags": ["model2"]}]`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/models?page=2>; rel="next"`, server.URL))
+		w.Write([]byte(`[{"id": "sha256:test1", "tags": ["model1"]}]`))
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	models, err := conv.FetchDMRModels(server.URL + "/models")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(models) != 2 || models[0].ID != "sha256:test1" || models[1].ID != "sha256:test2" {
+		t.Errorf("Expected models from both pages in order, got %+v", models)
+	}
+}
+
+func TestFetchDMRModelsFollowsBodyCursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			w.Write([]byte(`{"models": [{"id": "sha256:test1", "tags": ["model1"]}], "next_cursor": "abc"}`))
+		case "abc":
+			w.Write([]byte(`{"models": [{"id": "sha256:test2", "tags": ["model2"]}], "next": "/models?cursor=def"}`))
+		default:
+			w.Write([]byte(`{"models": [{"id": "sha256:test3", "tags": ["model3"]}]}`))
+		}
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	models, err := conv.FetchDMRModels(server.URL + "/models")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(models) != 3 {
+		t.Fatalf("Expected 3 models across pages, got %d", len(models))
+	}
+
+	if models[2].Tags[0] != "model3" {
+		t.Errorf("Expected last model 'model3', got '%s'", models[2].Tags[0])
+	}
+}
+
+func TestFetchDMRModelsPageLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// Every page points at another one
+		w.Write([]byte(fmt.Sprintf(`{"models": [{"id": "sha256:test%d"}], "next": "/models?page=%d"}`, requests, requests+1)))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	conv := NewConverter()
+	conv.MaxPages = 3
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	models, err := conv.FetchDMRModels(server.URL + "/models")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if requests != 3 || len(models) != 3 {
+		t.Errorf("Expected 3 pages and 3 models, got %d pages and %d models", requests, len(models))
+	}
+
+	if !strings.Contains(logs.String(), "page limit") {
+		t.Errorf("Expected page limit warning, got %q", logs.String())
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	tests := map[string]string{
+		`<http://dmr/models?page=2>; rel="next"`:                                       "http://dmr/models?page=2",
+		`<http://dmr/models?page=1>; rel="prev", <http://dmr/models?page=3>; rel=next`: "http://dmr/models?page=3",
+		`<http://dmr/models?page=1>; rel="prev"`:                                       "",
+		"":                                                                             "",
+	}
+
+	for header, expected := range tests {
+		if result := nextLink(header); result != expected {
+			t.Errorf("nextLink(%q) = '%s', expected '%s'", header, result, expected)
+		}
+	}
+}
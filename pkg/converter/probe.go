@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ProbeResult reports a single request to a DMR server made by Probe
+type ProbeResult struct {
+	URL string
+
+	// StatusCode is zero when the server couldn't be reached
+	StatusCode int
+
+	// Latency covers connecting, the request and reading the body
+	Latency time.Duration
+
+	// Models is the number of models on the first page of the response
+	Models int
+
+	// Err is nil only if the server answered 200 with a DMR model list
+	Err error
+}
+
+// Probe makes one request to a DMR server and checks that the response is
+// a model list, without following pagination or converting the models
+func (c *Converter) Probe(ctx context.Context, url string) ProbeResult {
+	result := ProbeResult{URL: url}
+
+	req, err := c.newDMRRequest(ctx, url)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		result.Latency = time.Since(start)
+		result.Err = fmt.Errorf("%w: %w", ErrFetch, err)
+		return result
+	}
+	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+
+	if resp.StatusCode != http.StatusOK {
+		result.Err = newHTTPStatusError(resp)
+		result.Latency = time.Since(start)
+		return result
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = fmt.Errorf("%w: failed to read response body: %w", ErrFetch, err)
+		return result
+	}
+
+	dmrModels, _, err := c.parseDMRPage(url, resp.Header, body)
+	result.Models = len(dmrModels)
+	result.Err = err
+	return result
+}
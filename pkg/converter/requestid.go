@@ -0,0 +1,50 @@
+package converter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to forward a request ID to DMR
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key holding the request ID
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying id. DMR fetches made with it
+// forward id in the X-Request-ID header and include it in log lines.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID generates a random request ID, for callers that didn't receive one
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// setRequestID forwards the request ID from req's context, if any
+func setRequestID(req *http.Request) {
+	if id := RequestIDFromContext(req.Context()); id != "" {
+		req.Header.Set(RequestIDHeader, id)
+	}
+}
+
+// contextLogger returns the logger, tagged with ctx's request ID if it has one
+func (c *Converter) contextLogger(ctx context.Context) *slog.Logger {
+	logger := c.logger()
+	if id := RequestIDFromContext(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	return logger
+}
@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDPropagation(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(RequestIDHeader))
+		w.Write([]byte(fmt.Sprintf(`{"models": [{"id": "sha256:test%d"}], "next": "/models?page=%d"}`, len(received), len(received)+1)))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	conv := NewConverter()
+	conv.MaxPages = 2
+	conv.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	id := NewRequestID()
+	if len(id) != 32 {
+		t.Errorf("Expected a 32 character request ID, got %q", id)
+	}
+
+	_, err := conv.FetchDMRModelsContext(WithRequestID(context.Background(), id), server.URL+"/models")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 DMR requests, got %d", len(received))
+	}
+	for i, header := range received {
+		if header != id {
+			t.Errorf("Expected request %d to carry ID %q, got %q", i, id, header)
+		}
+	}
+
+	if !strings.Contains(logs.String(), "request_id="+id) {
+		t.Errorf("Expected request ID in logs, got %q", logs.String())
+	}
+}
+
+func TestRequestIDAbsent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header[RequestIDHeader]; ok {
+			t.Errorf("Expected no %s header, got %q", RequestIDHeader, r.Header.Get(RequestIDHeader))
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	if _, err := conv.FetchDMRModels(server.URL); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if id := RequestIDFromContext(context.Background()); id != "" {
+		t.Errorf("Expected empty request ID, got %q", id)
+	}
+}
@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultTemplate is the Modelfile TEMPLATE used for families without a
+// configured template
+const DefaultTemplate = "{{ .Prompt }}"
+
+// OllamaShowResponse is the body of Ollama's POST /api/show endpoint
+type OllamaShowResponse struct {
+	License     string        `json:"license,omitempty"`
+	Modelfile   string        `json:"modelfile"`
+	Parameters  string        `json:"parameters"`
+	Template    string        `json:"template"`
+	Details     OllamaDetails `json:"details"`
+	Description string        `json:"description,omitempty"`
+
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// ConvertDMRToShow converts a single DMR model to an Ollama /api/show response
+func (c *Converter) ConvertDMRToShow(dmrModel DMRModel) OllamaShowResponse {
+	model := c.convertSingleModel(dmrModel)
+
+	template := DefaultTemplate
+	if familyTemplate, ok := c.Templates[model.Details.Family]; ok {
+		template = familyTemplate
+	}
+
+	parameters := modelParameters(dmrModel.Config)
+
+	return OllamaShowResponse{
+		License:     dmrModel.License,
+		Modelfile:   buildModelfile(model.Model, template, parameters),
+		Parameters:  strings.Join(parameters, "\n"),
+		Template:    template,
+		Details:     model.Details,
+		Description: model.Description,
+
+		Capabilities: modelCapabilities(dmrModel),
+	}
+}
+
+// modelParameters returns the Ollama PARAMETER values known from the DMR
+// config, formatted as "name value"
+func modelParameters(config DMRConfig) []string {
+	var parameters []string
+	if config.ContextSize > 0 {
+		parameters = append(parameters, fmt.Sprintf("num_ctx %d", config.ContextSize))
+	}
+	return parameters
+}
+
+// buildModelfile synthesizes a minimal Modelfile for clients that parse it
+func buildModelfile(model, template string, parameters []string) string {
+	var b strings.Builder
+	b.WriteString("# Modelfile generated by dmr-models-convert\n")
+	fmt.Fprintf(&b, "FROM %s\n", model)
+	fmt.Fprintf(&b, "TEMPLATE \"\"\"%s\"\"\"\n", template)
+	for _, parameter := range parameters {
+		fmt.Fprintf(&b, "PARAMETER %s\n", parameter)
+	}
+	return b.String()
+}
+
+// FindDMRModel returns the DMR model known by name, matching any of its tags,
+// its converted name, its digest, or its DMR ID with or without the
+// "sha256:" prefix. Names are compared case-insensitively.
+// A bare name without a tag finds its :latest tag, or its only variant; a
+// bare name with several variants and no :latest tag is ambiguous and not
+// found. With SynthesizeLatest, "<name>:latest" also finds the first
+// variant of name.
+func (c *Converter) FindDMRModel(dmrModels []DMRModel, name string) (DMRModel, bool) {
+	for _, dmrModel := range dmrModels {
+		for _, candidate := range c.modelCandidates(dmrModel) {
+			if strings.EqualFold(candidate, name) {
+				return dmrModel, true
+			}
+		}
+	}
+
+	base, tag := splitNameTag(name)
+	switch {
+	case tag == "":
+		if dmrModel, ok := c.FindDMRModel(dmrModels, name+":latest"); ok {
+			return dmrModel, true
+		}
+		if variants := c.variants(dmrModels, base); len(variants) == 1 {
+			return variants[0], true
+		}
+	case tag == "latest" && c.SynthesizeLatest:
+		// A synthesized :latest alias resolves to the first variant of its base name
+		if variants := c.variants(dmrModels, base); len(variants) > 0 {
+			return variants[0], true
+		}
+	}
+	return DMRModel{}, false
+}
+
+// modelCandidates returns the names FindDMRModel matches dmrModel by
+func (c *Converter) modelCandidates(dmrModel DMRModel) []string {
+	model := c.convertSingleModel(dmrModel)
+	candidates := []string{model.Name, model.Model, model.Digest}
+	if dmrModel.ID != "" {
+		candidates = append(candidates, dmrModel.ID, strings.TrimPrefix(dmrModel.ID, "sha256:"))
+	}
+	return append(candidates, dmrModel.Tags...)
+}
+
+// variants returns the DMR models with a tag or converted name whose base
+// name, without the tag, is base
+func (c *Converter) variants(dmrModels []DMRModel, base string) []DMRModel {
+	var variants []DMRModel
+	for _, dmrModel := range dmrModels {
+		candidates := append([]string{c.convertSingleModel(dmrModel).Name}, dmrModel.Tags...)
+		for _, candidate := range candidates {
+			if candidateBase, _ := splitNameTag(candidate); strings.EqualFold(candidateBase, base) {
+				variants = append(variants, dmrModel)
+				break
+			}
+		}
+	}
+	return variants
+}
@@ -0,0 +1,173 @@
+package converter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConvertDMRToShowDescriptionAndLicense(t *testing.T) {
+	jsonData := []byte(`[
+		{
+			"id": "sha256:test1",
+			"tags": ["ai/model1:latest"],
+			"created": 1745698622,
+			"config": {"format": "gguf", "architecture": "llama", "parameters": "1B", "quantization": "F16"},
+			"description": "A small test model",
+			"license": "Apache-2.0"
+		}
+	]`)
+
+	var dmrModels []DMRModel
+	err := json.Unmarshal(jsonData, &dmrModels)
+	if err != nil {
+		t.Fatalf("Expected valid DMR JSON, got error %v", err)
+	}
+
+	conv := NewConverter()
+	show := conv.ConvertDMRToShow(dmrModels[0])
+
+	if show.License != "Apache-2.0" {
+		t.Errorf("Expected license 'Apache-2.0', got '%s'", show.License)
+	}
+	if show.Description != "A small test model" {
+		t.Errorf("Expected description 'A small test model', got '%s'", show.Description)
+	}
+	if show.Details.Family != "llama" || show.Details.ParameterSize != "1B" {
+		t.Errorf("Expected converted details, got %+v", show.Details)
+	}
+
+	response := conv.ConvertDMRToOllama(dmrModels)
+	if response.Models[0].Description != "A small test model" {
+		t.Errorf("Expected description on the converted model, got '%s'", response.Models[0].Description)
+	}
+}
+
+func TestOllamaModelDescriptionOmitted(t *testing.T) {
+	jsonData, err := json.Marshal(OllamaModel{Name: "model1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var fields map[string]any
+	json.Unmarshal(jsonData, &fields)
+	if _, ok := fields["description"]; ok {
+		t.Error("Expected description to be omitted when empty")
+	}
+}
+
+func TestFindDMRModel(t *testing.T) {
+	dmrModels := []DMRModel{
+		{ID: "sha256:aaa", Tags: []string{"ai/model1:8B", "ai/model1:latest"}},
+		{ID: "sha256:bbb", Tags: []string{"ai/model2:latest"}},
+	}
+
+	conv := NewConverter()
+	for _, name := range []string{"ai/model1:latest", "AI/Model1:8B", "aaa"} {
+		model, ok := conv.FindDMRModel(dmrModels, name)
+		if !ok || model.ID != "sha256:aaa" {
+			t.Errorf("Expected '%s' to find model1, got %v", name, model.ID)
+		}
+	}
+
+	if _, ok := conv.FindDMRModel(dmrModels, "ai/model3:latest"); ok {
+		t.Error("Expected unknown model not to be found")
+	}
+}
+
+func TestFindDMRModelByID(t *testing.T) {
+	// The converted digest is the weights layer's, so only the ID names the model
+	dmrModels := []DMRModel{
+		{ID: "sha256:aaa", Tags: []string{"ai/model1:latest"}, Digests: []string{"sha256:weights"}},
+	}
+
+	conv := NewConverter()
+	for _, name := range []string{"sha256:aaa", "aaa", "SHA256:AAA", "weights"} {
+		if model, ok := conv.FindDMRModel(dmrModels, name); !ok || model.ID != "sha256:aaa" {
+			t.Errorf("Expected '%s' to find model1, got %v", name, model.ID)
+		}
+	}
+}
+
+func TestConvertDMRToShowModelfile(t *testing.T) {
+	dmrModel := DMRModel{
+		ID:   "sha256:test1",
+		Tags: []string{"ai/qwen3:8B-F16"},
+		Config: DMRConfig{
+			Architecture: "qwen3",
+			ContextSize:  40960,
+		},
+	}
+
+	conv := NewConverter()
+	conv.Templates = map[string]string{
+		"qwen": "<|im_start|>user\n{{ .Prompt }}<|im_end|>",
+	}
+	show := conv.ConvertDMRToShow(dmrModel)
+
+	if !strings.Contains(show.Modelfile, "FROM ai/qwen3:8B-F16\n") {
+		t.Errorf("Expected FROM line in modelfile, got %q", show.Modelfile)
+	}
+	if !strings.Contains(show.Modelfile, "PARAMETER num_ctx 40960\n") {
+		t.Errorf("Expected num_ctx parameter in modelfile, got %q", show.Modelfile)
+	}
+	if show.Parameters != "num_ctx 40960" {
+		t.Errorf("Expected parameters 'num_ctx 40960', got %q", show.Parameters)
+	}
+	if show.Template != conv.Templates["qwen"] {
+		t.Errorf("Expected family template, got %q", show.Template)
+	}
+	if !strings.Contains(show.Modelfile, `TEMPLATE """<|im_start|>user`) {
+		t.Errorf("Expected family template in modelfile, got %q", show.Modelfile)
+	}
+}
+
+func TestConvertDMRToShowDefaultTemplate(t *testing.T) {
+	conv := NewConverter()
+	show := conv.ConvertDMRToShow(DMRModel{ID: "sha256:test1", Tags: []string{"model1"}})
+
+	if show.Template != DefaultTemplate {
+		t.Errorf("Expected default template, got %q", show.Template)
+	}
+	if show.Parameters != "" || strings.Contains(show.Modelfile, "PARAMETER") {
+		t.Errorf("Expected no parameters without a context size, got %q", show.Modelfile)
+	}
+}
+
+func TestFindDMRModelBareName(t *testing.T) {
+	dmrModels := []DMRModel{
+		{ID: "sha256:aaa", Tags: []string{"ai/model1:8B"}},
+		{ID: "sha256:bbb", Tags: []string{"ai/model1:latest"}},
+		{ID: "sha256:ccc", Tags: []string{"ai/model2:4B"}},
+		{ID: "sha256:ddd", Tags: []string{"ai/model3:1B"}},
+		{ID: "sha256:eee", Tags: []string{"ai/model3:8B"}},
+	}
+
+	conv := NewConverter()
+	tests := map[string]string{
+		"ai/model1": "sha256:bbb", // prefers :latest
+		"AI/Model2": "sha256:ccc", // the only variant
+	}
+	for name, expected := range tests {
+		model, ok := conv.FindDMRModel(dmrModels, name)
+		if !ok || model.ID != expected {
+			t.Errorf("Expected '%s' to find %s, got %v", name, expected, model.ID)
+		}
+	}
+
+	// Several variants and no :latest is ambiguous
+	if model, ok := conv.FindDMRModel(dmrModels, "ai/model3"); ok {
+		t.Errorf("Expected ambiguous 'ai/model3' not to be found, got %v", model.ID)
+	}
+
+	// With synthesized :latest aliases the first variant wins
+	conv.SynthesizeLatest = true
+	model, ok := conv.FindDMRModel(dmrModels, "ai/model3")
+	if !ok || model.ID != "sha256:ddd" {
+		t.Errorf("Expected 'ai/model3' to find its first variant, got %v", model.ID)
+	}
+
+	if _, ok := conv.FindDMRModel(dmrModels, "ai/model4"); ok {
+		t.Error("Expected unknown bare name not to be found")
+	}
+}
@@ -0,0 +1,40 @@
+package converter
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseSince parses a --since value, either a duration like "24h" counted
+// back from now or an RFC3339 timestamp like "2025-01-02T15:04:05Z"
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid since %q: duration must not be negative", value)
+		}
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q: expected a duration like 24h or an RFC3339 timestamp", value)
+	}
+	return t, nil
+}
+
+// FilterSince returns the models created at or after since, keeping their
+// order. A zero since keeps every model.
+func FilterSince(dmrModels []DMRModel, since time.Time) []DMRModel {
+	if since.IsZero() {
+		return dmrModels
+	}
+
+	cutoff := since.Unix()
+	filtered := make([]DMRModel, 0, len(dmrModels))
+	for _, dmrModel := range dmrModels {
+		if dmrModel.Created >= cutoff {
+			filtered = append(filtered, dmrModel)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,64 @@
+package converter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"24h":                       time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		"90m":                       time.Date(2025, 6, 2, 10, 30, 0, 0, time.UTC),
+		"2025-01-02T15:04:05Z":      time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC),
+		"2025-01-02T15:04:05+02:00": time.Date(2025, 1, 2, 13, 4, 5, 0, time.UTC),
+	}
+
+	for value, expected := range tests {
+		since, err := ParseSince(value, now)
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", value, err)
+			continue
+		}
+		if !since.Equal(expected) {
+			t.Errorf("%s: expected %s, got %s", value, expected, since)
+		}
+	}
+
+	for _, value := range []string{"", "yesterday", "-1h", "2025-01-02"} {
+		if _, err := ParseSince(value, now); err == nil {
+			t.Errorf("%q: expected an error, got nil", value)
+		}
+	}
+}
+
+func TestFilterSince(t *testing.T) {
+	since := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	dmrModels := []DMRModel{
+		{ID: "sha256:old", Created: since.Unix() - 1},
+		{ID: "sha256:exact", Created: since.Unix()},
+		{ID: "sha256:new", Created: since.Unix() + 3600},
+	}
+
+	filtered := FilterSince(dmrModels, since)
+	if len(filtered) != 2 {
+		t.Fatalf("Expected 2 models, got %d", len(filtered))
+	}
+	if filtered[0].ID != "sha256:exact" || filtered[1].ID != "sha256:new" {
+		t.Errorf("Expected exact and new models in order, got %s and %s", filtered[0].ID, filtered[1].ID)
+	}
+
+	if len(FilterSince(dmrModels, time.Time{})) != 3 {
+		t.Error("Expected a zero since to keep every model")
+	}
+
+	conv := NewConverter()
+	conv.Since = time.Now().Add(-24 * time.Hour)
+	result := conv.ConvertDMRToOllama([]DMRModel{
+		{ID: "sha256:old", Tags: []string{"old"}, Created: since.Unix()},
+		{ID: "sha256:new", Tags: []string{"new"}, Created: time.Now().Unix()},
+	})
+	if len(result.Models) != 1 || result.Models[0].Name != "new" {
+		t.Errorf("Expected only the new model, got %+v", result.Models)
+	}
+}
@@ -0,0 +1,120 @@
+package converter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultModelPath is the path of DMR's single-model endpoint, relative to
+// the models URL
+const DefaultModelPath = "/{name}"
+
+// FetchDMRModel fetches the DMR model known by nameOrDigest from the DMR
+// models endpoint at modelsURL. It asks DMR's single-model endpoint at
+// ModelPath first. When that answers 404, 405 or 501, because the endpoint
+// isn't available or DMR doesn't know the name, it falls back to fetching
+// the whole list and finding the model with FindDMRModel. A model found
+// neither way is reported with ErrModelNotFound.
+func (c *Converter) FetchDMRModel(ctx context.Context, modelsURL, nameOrDigest string) (DMRModel, error) {
+	ctx, span := c.startSpan(ctx, "dmr.fetch_model", attribute.String("url.full", modelsURL))
+	dmrModel, err := c.fetchDMRModel(ctx, modelsURL, nameOrDigest)
+	endSpan(span, err)
+	return dmrModel, err
+}
+
+// fetchDMRModel does the work of FetchDMRModel, outside its span
+func (c *Converter) fetchDMRModel(ctx context.Context, modelsURL, nameOrDigest string) (DMRModel, error) {
+	dmrModel, ok, err := c.fetchSingleModel(ctx, c.modelURL(modelsURL, nameOrDigest))
+	if err != nil || ok {
+		return dmrModel, err
+	}
+
+	c.contextLogger(ctx).Debug("DMR single-model endpoint unavailable, fetching all models", "model", nameOrDigest)
+	dmrModels, err := c.fetchDMRModels(ctx, modelsURL)
+	if err != nil {
+		return DMRModel{}, err
+	}
+
+	dmrModel, ok = c.FindDMRModel(dmrModels, nameOrDigest)
+	if !ok {
+		return DMRModel{}, fmt.Errorf("%w: %s", ErrModelNotFound, nameOrDigest)
+	}
+	return dmrModel, nil
+}
+
+// fetchSingleModel requests one model from DMR's single-model endpoint,
+// reporting false without an error when the endpoint can't answer
+func (c *Converter) fetchSingleModel(ctx context.Context, modelURL string) (DMRModel, bool, error) {
+	req, err := c.newDMRRequest(ctx, modelURL)
+	if err != nil {
+		return DMRModel{}, false, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return DMRModel{}, false, fmt.Errorf("%w: %w", ErrFetch, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return DMRModel{}, false, nil
+	default:
+		return DMRModel{}, false, newHTTPStatusError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return DMRModel{}, false, fmt.Errorf("%w: failed to read response body: %w", ErrFetch, err)
+	}
+
+	dmrModel, err := c.parseDMRModel(body)
+	if err != nil {
+		return DMRModel{}, false, err
+	}
+	return dmrModel, true, nil
+}
+
+// parseDMRModel decodes a single DMR model, applying any custom field paths
+func (c *Converter) parseDMRModel(data []byte) (DMRModel, error) {
+	var dmrModel DMRModel
+	if err := json.Unmarshal(data, &dmrModel); err != nil {
+		return DMRModel{}, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+
+	if len(c.FieldPaths) == 0 {
+		return dmrModel, nil
+	}
+
+	var rawModel map[string]any
+	if err := json.Unmarshal(data, &rawModel); err != nil {
+		return DMRModel{}, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+	if err := applyFieldPaths(&dmrModel.Config, rawModel, c.FieldPaths); err != nil {
+		return DMRModel{}, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+	return dmrModel, nil
+}
+
+// modelURL returns the single-model endpoint for name under modelsURL,
+// escaping each "/"-separated part of the name
+func (c *Converter) modelURL(modelsURL, name string) string {
+	path := c.ModelPath
+	if path == "" {
+		path = DefaultModelPath
+	}
+
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.TrimSuffix(modelsURL, "/") + strings.ReplaceAll(path, "{name}", strings.Join(parts, "/"))
+}
@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchDMRModelDirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/ai/model1:8B" && r.URL.Path != "/models/ai/model1:8B/info" {
+			t.Errorf("Expected only the single-model endpoint to be requested, got %s", r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"id": "sha256:aaa", "tags": ["ai/model1:8B"], "config": {"format": "gguf"}}`))
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	dmrModel, err := conv.FetchDMRModel(context.Background(), server.URL+"/models", "ai/model1:8B")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if dmrModel.ID != "sha256:aaa" || dmrModel.Config.Format != "gguf" {
+		t.Errorf("Expected model sha256:aaa in gguf format, got %+v", dmrModel)
+	}
+
+	// A custom path is appended to the models URL
+	conv.ModelPath = "/{name}/info"
+	dmrModel, err = conv.FetchDMRModel(context.Background(), server.URL+"/models/", "ai/model1:8B")
+	if err != nil || dmrModel.ID != "sha256:aaa" {
+		t.Errorf("Expected model sha256:aaa from the custom path, got %s (%v)", dmrModel.ID, err)
+	}
+}
+
+func TestFetchDMRModelFallback(t *testing.T) {
+	var listRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			http.NotFound(w, r)
+			return
+		}
+		listRequests++
+		w.Write([]byte(`[{"id": "sha256:aaa", "tags": ["ai/model1:8B"]}, {"id": "sha256:bbb", "tags": ["ai/model2:latest"]}]`))
+	}))
+	defer server.Close()
+
+	conv := NewConverter()
+	dmrModel, err := conv.FetchDMRModel(context.Background(), server.URL+"/models", "ai/model2")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if dmrModel.ID != "sha256:bbb" {
+		t.Errorf("Expected model sha256:bbb, got %s", dmrModel.ID)
+	}
+	if listRequests != 1 {
+		t.Errorf("Expected 1 request for the model list, got %d", listRequests)
+	}
+
+	_, err = conv.FetchDMRModel(context.Background(), server.URL+"/models", "ai/model3")
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got %v", err)
+	}
+}
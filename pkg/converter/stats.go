@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Stats summarizes a converted model list
+type Stats struct {
+	TotalModels   int                 `json:"total_models"`
+	Families      map[string]int      `json:"families"`
+	FamilyModels  map[string][]string `json:"family_models"`
+	TotalSize     int64               `json:"total_size"`
+	AverageSize   int64               `json:"average_size"`
+	SmallestModel *ModelSize          `json:"smallest_model,omitempty"`
+	LargestModel  *ModelSize          `json:"largest_model,omitempty"`
+}
+
+// ModelSize identifies a model together with its size in bytes
+type ModelSize struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// ComputeStats calculates counts and size statistics for a response
+func ComputeStats(response OllamaResponse) Stats {
+	stats := Stats{
+		TotalModels:  len(response.Models),
+		Families:     make(map[string]int),
+		FamilyModels: make(map[string][]string),
+	}
+
+	for _, model := range response.Models {
+		stats.Families[model.Details.Family]++
+		stats.FamilyModels[model.Details.Family] = append(stats.FamilyModels[model.Details.Family], model.Name)
+		stats.TotalSize += model.Size
+
+		if stats.SmallestModel == nil || model.Size < stats.SmallestModel.Size {
+			stats.SmallestModel = &ModelSize{Name: model.Name, Size: model.Size}
+		}
+		if stats.LargestModel == nil || model.Size > stats.LargestModel.Size {
+			stats.LargestModel = &ModelSize{Name: model.Name, Size: model.Size}
+		}
+	}
+
+	if stats.TotalModels > 0 {
+		stats.AverageSize = stats.TotalSize / int64(stats.TotalModels)
+	}
+
+	return stats
+}
+
+// SortedFamilies returns the family names in the stats in alphabetical order
+func (s Stats) SortedFamilies() []string {
+	families := make([]string, 0, len(s.Families))
+	for family := range s.Families {
+		families = append(families, family)
+	}
+	sort.Strings(families)
+	return families
+}
+
+// FormatSize converts a size in bytes to a human-readable string like
+// "690.24 MiB", using the same units DMR reports and parseSizeString reads
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	units := []string{"KiB", "MiB", "GiB"}
+	i := -1
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+
+	return fmt.Sprintf("%.2f %s", value, units[i])
+}
@@ -0,0 +1,70 @@
+package converter
+
+import "testing"
+
+func TestComputeStats(t *testing.T) {
+	response := OllamaResponse{
+		Models: []OllamaModel{
+			{Name: "small", Size: 100, Details: OllamaDetails{Family: "llama"}},
+			{Name: "medium", Size: 200, Details: OllamaDetails{Family: "llama"}},
+			{Name: "large", Size: 600, Details: OllamaDetails{Family: "qwen"}},
+		},
+	}
+
+	stats := ComputeStats(response)
+
+	if stats.TotalModels != 3 {
+		t.Errorf("Expected 3 models, got %d", stats.TotalModels)
+	}
+
+	if stats.Families["llama"] != 2 || stats.Families["qwen"] != 1 {
+		t.Errorf("Expected families llama=2 qwen=1, got %v", stats.Families)
+	}
+
+	if llama := stats.FamilyModels["llama"]; len(llama) != 2 || llama[0] != "small" || llama[1] != "medium" {
+		t.Errorf("Expected llama models [small medium], got %v", llama)
+	}
+	if qwen := stats.FamilyModels["qwen"]; len(qwen) != 1 || qwen[0] != "large" {
+		t.Errorf("Expected qwen models [large], got %v", qwen)
+	}
+
+	if stats.TotalSize != 900 {
+		t.Errorf("Expected total size 900, got %d", stats.TotalSize)
+	}
+
+	if stats.AverageSize != 300 {
+		t.Errorf("Expected average size 300, got %d", stats.AverageSize)
+	}
+
+	if stats.SmallestModel.Name != "small" || stats.LargestModel.Name != "large" {
+		t.Errorf("Expected smallest 'small' and largest 'large', got '%s' and '%s'", stats.SmallestModel.Name, stats.LargestModel.Name)
+	}
+}
+
+func TestComputeStatsEmpty(t *testing.T) {
+	stats := ComputeStats(OllamaResponse{})
+
+	if stats.TotalModels != 0 || stats.AverageSize != 0 {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+
+	if stats.SmallestModel != nil || stats.LargestModel != nil {
+		t.Error("Expected no smallest or largest model for empty response")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:                    "512 B",
+		1024:                   "1.00 KiB",
+		723769344:              "690.24 MiB",
+		1024 * 1024 * 1024:     "1.00 GiB",
+		3 * 1024 * 1024 * 1024: "3.00 GiB",
+	}
+
+	for input, expected := range tests {
+		if result := FormatSize(input); result != expected {
+			t.Errorf("FormatSize(%d) = %q, expected %q", input, result, expected)
+		}
+	}
+}
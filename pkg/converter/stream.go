@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StreamConvert converts DMR models and writes the Ollama response to w one
+// model at a time, so the full response is never held in memory. The output
+// is byte-for-byte the same as json.MarshalIndent(response, "", "  ").
+func (c *Converter) StreamConvert(w io.Writer, dmrModels []DMRModel) error {
+	_, err := c.StreamConvertCount(w, dmrModels)
+	return err
+}
+
+// StreamConvertCount is StreamConvert, also returning how many models it
+// wrote, e.g. to tell an empty response apart
+func (c *Converter) StreamConvertCount(w io.Writer, dmrModels []DMRModel) (int, error) {
+	_, err := io.WriteString(w, "{\n  \"models\": [")
+	if err != nil {
+		return 0, err
+	}
+
+	written := 0
+	writeModel := func(model OllamaModel) error {
+		jsonData, err := json.MarshalIndent(model, "    ", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+
+		separator := ",\n    "
+		if written == 0 {
+			separator = "\n    "
+		}
+		_, err = io.WriteString(w, separator)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(jsonData)
+		if err != nil {
+			return err
+		}
+		written++
+		return nil
+	}
+
+	err = c.eachModel(context.Background(), dmrModels, false, writeModel)
+	if err != nil {
+		return written, err
+	}
+
+	closing := "]\n}"
+	if written > 0 {
+		closing = "\n  ]\n}"
+	}
+	_, err = io.WriteString(w, closing)
+	return written, err
+}
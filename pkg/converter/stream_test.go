@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestStreamConvertMatchesBuffered(t *testing.T) {
+	tests := map[string][]DMRModel{
+		"empty":  {},
+		"single": benchmarkDMRModels(1),
+		"many":   benchmarkDMRModels(25),
+	}
+
+	conv := NewConverter()
+	for name, dmrModels := range tests {
+		t.Run(name, func(t *testing.T) {
+			expected, err := json.MarshalIndent(conv.ConvertDMRToOllama(dmrModels), "", "  ")
+			if err != nil {
+				t.Fatalf("Expected no error marshaling, got %v", err)
+			}
+
+			var streamed bytes.Buffer
+			err = conv.StreamConvert(&streamed, dmrModels)
+			if err != nil {
+				t.Fatalf("Expected no error streaming, got %v", err)
+			}
+
+			if streamed.String() != string(expected) {
+				t.Errorf("Expected streamed output to match buffered output\nstreamed:\n%s\nbuffered:\n%s", streamed.String(), expected)
+			}
+		})
+	}
+}
+
+func TestStreamConvertAppliesTransform(t *testing.T) {
+	conv := NewConverter()
+	conv.ModelTransform = func(model OllamaModel) OllamaModel {
+		if model.Name == "ai/model0:latest" {
+			return OllamaModel{}
+		}
+		return model
+	}
+
+	var streamed bytes.Buffer
+	err := conv.StreamConvert(&streamed, benchmarkDMRModels(3))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var response OllamaResponse
+	err = json.Unmarshal(streamed.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Expected valid JSON, got error %v", err)
+	}
+
+	if len(response.Models) != 2 {
+		t.Errorf("Expected dropped model to be left out, got %d models", len(response.Models))
+	}
+}
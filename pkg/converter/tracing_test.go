@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestConvertFromSpans(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":"sha256:test1","tags":["model1"],"config":{"architecture":"llama"}}]`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	conv := NewConverter()
+	conv.Tracer = tracer
+
+	ctx, parent := tracer.Start(context.Background(), "request")
+	_, err := conv.ConvertFrom(ctx, conv.HTTPFetcher(server.URL))
+	parent.End()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+
+	expected := []string{"dmr.fetch", "dmr.convert", "request"}
+	for i, name := range expected {
+		if spans[i].Name() != name {
+			t.Errorf("Expected span %d to be %q, got %q", i, name, spans[i].Name())
+		}
+	}
+
+	for _, span := range spans[:2] {
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("Expected span %q to be a child of the request span", span.Name())
+		}
+	}
+}
+
+func TestFetchSpanRecordsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	conv := NewConverter()
+	conv.Tracer = provider.Tracer("test")
+
+	_, err := conv.FetchDMRModels(server.URL)
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", spans[0].Status().Code)
+	}
+}
+
+func TestNoTracerRecordsNothing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	conv := NewConverter()
+	_, err := conv.ConvertFrom(ctx, &fakeFetcher{})
+	parent.End()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if spans := recorder.Ended(); len(spans) != 1 {
+		t.Errorf("Expected only the request span, got %d spans", len(spans))
+	}
+}
@@ -0,0 +1,36 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidResponse is wrapped by every violation ValidateOllamaResponse reports
+var ErrInvalidResponse = errors.New("invalid Ollama response")
+
+// ValidateOllamaResponse checks that every model has the fields Ollama
+// clients rely on: a name, an RFC3339 modified_at, a non-negative size and
+// a family. All violations are returned joined together.
+func ValidateOllamaResponse(response OllamaResponse) error {
+	var errs []error
+	for i, model := range response.Models {
+		invalid := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("%w: model %d (%q): %s", ErrInvalidResponse, i, model.Name, fmt.Sprintf(format, args...)))
+		}
+
+		if model.Name == "" {
+			invalid("empty name")
+		}
+		if _, err := time.Parse(time.RFC3339, model.ModifiedAt); err != nil {
+			invalid("modified_at %q is not an RFC3339 time", model.ModifiedAt)
+		}
+		if model.Size < 0 {
+			invalid("negative size %d", model.Size)
+		}
+		if model.Details.Family == "" {
+			invalid("empty family")
+		}
+	}
+	return errors.Join(errs...)
+}
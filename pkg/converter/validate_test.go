@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateOllamaResponse(t *testing.T) {
+	valid := OllamaModel{
+		Name:       "model1",
+		ModifiedAt: "2025-04-26T20:17:02Z",
+		Size:       1024,
+		Details:    OllamaDetails{Family: "llama"},
+	}
+
+	if err := ValidateOllamaResponse(OllamaResponse{Models: []OllamaModel{valid}}); err != nil {
+		t.Errorf("Expected valid response, got %v", err)
+	}
+
+	if err := ValidateOllamaResponse(OllamaResponse{}); err != nil {
+		t.Errorf("Expected empty response to be valid, got %v", err)
+	}
+
+	tests := map[string]func(*OllamaModel){
+		"empty name":   func(m *OllamaModel) { m.Name = "" },
+		"modified_at":  func(m *OllamaModel) { m.ModifiedAt = "yesterday" },
+		"negative":     func(m *OllamaModel) { m.Size = -1 },
+		"empty family": func(m *OllamaModel) { m.Details.Family = "" },
+	}
+
+	for expected, breakModel := range tests {
+		model := valid
+		breakModel(&model)
+
+		err := ValidateOllamaResponse(OllamaResponse{Models: []OllamaModel{valid, model}})
+		if !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("%s: expected ErrInvalidResponse, got %v", expected, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), "model 1") || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected the violation for model 1, got %q", expected, err.Error())
+		}
+	}
+
+	// Every violation is listed
+	err := ValidateOllamaResponse(OllamaResponse{Models: []OllamaModel{{Size: -1}}})
+	if lines := strings.Count(err.Error(), "\n") + 1; lines != 4 {
+		t.Errorf("Expected 4 violations, got %d: %q", lines, err.Error())
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// probeCmd represents the probe command
+var probeCmd = &cobra.Command{
+	Use:   "probe",
+	Short: "Check that each DMR server answers with a model list",
+	Long: `Make one request to each DMR server and report its HTTP status, latency,
+and whether the body parsed as a DMR model list, without converting
+anything. Exits non-zero if any server fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return probeServers(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(probeCmd)
+}
+
+// probeServers probes every DMR server in turn, writing one line per
+// server to w, and returns the failures joined together
+func probeServers(w io.Writer) error {
+	conv := newConverter()
+	defer conv.Close()
+
+	var errs []error
+	for _, dmrURL := range expandDMRURLs(dmrURLs) {
+		result := conv.Probe(context.Background(), dmrURL)
+		latency := result.Latency.Round(time.Millisecond)
+
+		switch {
+		case result.StatusCode == 0:
+			fmt.Fprintf(w, "%s: unreachable after %s: %v\n", dmrURL, latency, result.Err)
+		case result.Err != nil:
+			fmt.Fprintf(w, "%s: status %d %s in %s: %v\n", dmrURL, result.StatusCode, http.StatusText(result.StatusCode), latency, result.Err)
+		default:
+			fmt.Fprintf(w, "%s: status %d %s in %s, %d models\n", dmrURL, result.StatusCode, http.StatusText(result.StatusCode), latency, result.Models)
+		}
+
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", dmrURL, result.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
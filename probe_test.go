@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dmr-models-convert/pkg/converter"
+)
+
+func TestProbeServers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/html" {
+			w.Write([]byte(`<html>login</html>`))
+			return
+		}
+		w.Write([]byte(`[{"id": "sha256:aaa", "tags": ["model1"]}, {"id": "sha256:bbb", "tags": ["model2"]}]`))
+	}))
+	defer server.Close()
+
+	// Grab a free port, then close it so nothing is listening
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL := closed.URL
+	closed.Close()
+
+	savedURLs := dmrURLs
+	defer func() { dmrURLs = savedURLs }()
+
+	tests := map[string]struct {
+		url      string
+		expected string
+		err      error
+	}{
+		"reachable":   {server.URL + "/models", "status 200 OK", nil},
+		"unreachable": {closedURL, "unreachable", converter.ErrFetch},
+		"non-JSON":    {server.URL + "/html", "status 200 OK", converter.ErrParse},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dmrURLs = []string{test.url}
+
+			var buf bytes.Buffer
+			err := probeServers(&buf)
+			if test.err == nil && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Errorf("Expected %v, got %v", test.err, err)
+			}
+
+			if !strings.Contains(buf.String(), test.expected) {
+				t.Errorf("Expected %q in output, got %q", test.expected, buf.String())
+			}
+			if test.err == nil && !strings.Contains(buf.String(), "2 models") {
+				t.Errorf("Expected model count in output, got %q", buf.String())
+			}
+		})
+	}
+}
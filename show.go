@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"dmr-models-convert/pkg/converter"
+
+	"github.com/spf13/cobra"
+)
+
+// showCmd represents the show command
+var showCmd = &cobra.Command{
+	Use:   "show <model>",
+	Short: "Print the Ollama /api/show response for a DMR model",
+	Long: `Fetch the DMR models and print the Ollama /api/show JSON for the named
+model, or save it to the output file. The model can be named by any of its
+tags, its converted name, or its digest, ignoring case. A name without a
+tag, like "ai/llama3.2", finds its :latest tag or its only variant.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conv := newConverter()
+		defer conv.Close()
+
+		dmrModel, err := findDMRModel(conv, args[0])
+		if err != nil {
+			return err
+		}
+
+		jsonData, err := json.MarshalIndent(conv.ConvertDMRToShow(dmrModel), "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+
+		if !writesToStdout() {
+			err = writeOutputFile(output, jsonData)
+			if err != nil {
+				return outputError{fmt.Errorf("saving output file: %w", err)}
+			}
+			fmt.Fprintf(os.Stderr, "Successfully saved to: %s\n", output)
+		} else {
+			fmt.Println(string(jsonData))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+}
+
+// findDMRModel finds the named DMR model. With a single DMR server and no
+// --file it asks DMR for just that model, falling back to the full list.
+func findDMRModel(conv *converter.Converter, name string) (converter.DMRModel, error) {
+	urls := expandDMRURLs(dmrURLs)
+	if len(dmrFiles) == 0 && len(urls) == 1 {
+		dmrModel, err := conv.FetchDMRModel(context.Background(), urls[0], name)
+		if errors.Is(err, converter.ErrModelNotFound) {
+			return converter.DMRModel{}, fmt.Errorf("model %q not found", name)
+		}
+		if err != nil {
+			return converter.DMRModel{}, fmt.Errorf("fetching DMR model: %w", err)
+		}
+		return dmrModel, nil
+	}
+
+	dmrModels, err := loadDMRModels(conv)
+	if err != nil {
+		return converter.DMRModel{}, fmt.Errorf("fetching DMR models: %w", err)
+	}
+
+	dmrModel, ok := conv.FindDMRModel(dmrModels, name)
+	if !ok {
+		return converter.DMRModel{}, fmt.Errorf("model %q not found", name)
+	}
+	return dmrModel, nil
+}
+
+// loadDMRModels reads the unconverted DMR models from the DMR files when
+// --file is set, or from all configured DMR servers
+func loadDMRModels(conv *converter.Converter) ([]converter.DMRModel, error) {
+	if len(dmrFiles) == 0 {
+		return conv.FetchDMRModelsFromURLs(context.Background(), expandDMRURLs(dmrURLs))
+	}
+
+	dmrModels, _, err := readDMRFiles(conv, dmrFiles)
+	return dmrModels, err
+}
+
+// readDMRFiles reads the DMR models from the files matching the given
+// paths or globs, in order, merging models with the same digest across
+// files. It returns the number of files read.
+func readDMRFiles(conv *converter.Converter, patterns []string) ([]converter.DMRModel, int, error) {
+	filenames, err := expandGlobs(patterns)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var dmrModels []converter.DMRModel
+	for _, filename := range filenames {
+		jsonData, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		models, err := conv.ParseDMRModels(jsonData)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", filename, err)
+		}
+		dmrModels = append(dmrModels, models...)
+	}
+
+	return converter.MergeDMRModels(dmrModels), len(filenames), nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDMRModelsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.json"), []byte(`[{"id": "sha256:aaa", "tags": ["model1"], "license": "MIT"}]`), 0644)
+	os.WriteFile(filepath.Join(dir, "b.json"), []byte(`[{"id": "sha256:bbb", "tags": ["model2"]}]`), 0644)
+
+	dmrFiles = []string{filepath.Join(dir, "*.json")}
+	defer func() { dmrFiles = nil }()
+
+	conv := newConverter()
+	dmrModels, err := loadDMRModels(conv)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(dmrModels) != 2 {
+		t.Fatalf("Expected 2 DMR models, got %d", len(dmrModels))
+	}
+
+	dmrModel, ok := conv.FindDMRModel(dmrModels, "model1")
+	if !ok {
+		t.Fatal("Expected model1 to be found")
+	}
+
+	if show := conv.ConvertDMRToShow(dmrModel); show.License != "MIT" {
+		t.Errorf("Expected license 'MIT' in show output, got '%s'", show.License)
+	}
+}
+
+func TestShowCmdErrors(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.json"), []byte(`[{"id": "sha256:aaa", "tags": ["model1"]}]`), 0644)
+
+	dmrFiles = []string{filepath.Join(dir, "a.json")}
+	output = filepath.Join(dir, "missing", "show.json")
+	defer func() { dmrFiles, output = nil, "" }()
+
+	err := showCmd.RunE(showCmd, []string{"model2"})
+	if err == nil || !strings.Contains(err.Error(), `model "model2" not found`) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	err = showCmd.RunE(showCmd, []string{"model1"})
+	if exitCode(err) != exitWrite {
+		t.Errorf("Expected write failure exit code %d, got %d for error %v", exitWrite, exitCode(err), err)
+	}
+}
+
+func TestFindDMRModelSingleEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/ai/model1:8B" {
+			t.Errorf("Expected a request for the single model, got %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"id": "sha256:aaa", "tags": ["ai/model1:8B"], "license": "MIT"}`))
+	}))
+	defer server.Close()
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL + "/models"}
+	defer func() { dmrURLs = savedURLs }()
+
+	dmrModel, err := findDMRModel(newConverter(), "ai/model1:8B")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if dmrModel.License != "MIT" {
+		t.Errorf("Expected license 'MIT', got '%s'", dmrModel.License)
+	}
+}
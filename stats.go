@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"dmr-models-convert/pkg/converter"
+
+	"github.com/spf13/cobra"
+)
+
+var statsFormat string
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Print summary statistics for the DMR models",
+	Long: `Fetch and convert the DMR models, then print the total model count,
+the number of models per family, total and average size, and the
+smallest and largest models. JSON output also lists the model names
+in each family.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ollamaResponse, err := fetchModels()
+		if err != nil {
+			return fmt.Errorf("converting DMR models: %w", err)
+		}
+
+		err = printStats(os.Stdout, converter.ComputeStats(ollamaResponse), statsFormat)
+		if err != nil {
+			return outputError{fmt.Errorf("printing stats: %w", err)}
+		}
+		return nil
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsFormat, "format", "text", "Output format: text or json")
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+// printStats writes the stats in the requested format
+func printStats(w io.Writer, stats converter.Stats, format string) error {
+	switch format {
+	case "json":
+		jsonData, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(jsonData))
+	case "text":
+		fmt.Fprintf(w, "Total models: %d\n", stats.TotalModels)
+		fmt.Fprintf(w, "Total size: %s\n", converter.FormatSize(stats.TotalSize))
+		fmt.Fprintf(w, "Average size: %s\n", converter.FormatSize(stats.AverageSize))
+		if stats.SmallestModel != nil {
+			fmt.Fprintf(w, "Smallest model: %s (%s)\n", stats.SmallestModel.Name, converter.FormatSize(stats.SmallestModel.Size))
+			fmt.Fprintf(w, "Largest model: %s (%s)\n", stats.LargestModel.Name, converter.FormatSize(stats.LargestModel.Size))
+		}
+		fmt.Fprintln(w, "Families:")
+		for _, family := range stats.SortedFamilies() {
+			fmt.Fprintf(w, "  %s: %d\n", family, stats.Families[family])
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	return nil
+}
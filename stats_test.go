@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dmr-models-convert/pkg/converter"
+)
+
+func TestStatsFromStubServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": "sha256:aaa", "tags": ["model1"], "created": 1745698622, "config": {"architecture": "llama", "size": "1 GiB"}},
+			{"id": "sha256:bbb", "tags": ["model2"], "created": 1745698622, "config": {"architecture": "llama3", "size": "3 GiB"}},
+			{"id": "sha256:ccc", "tags": ["model3"], "created": 1745698622, "config": {"architecture": "qwen3", "size": "2 GiB"}}
+		]`))
+	}))
+	defer server.Close()
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	defer func() { dmrURLs = savedURLs }()
+
+	response, err := fetchModels()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = printStats(&buf, converter.ComputeStats(response), "json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var stats converter.Stats
+	err = json.Unmarshal(buf.Bytes(), &stats)
+	if err != nil {
+		t.Fatalf("Expected valid JSON, got error %v", err)
+	}
+
+	const gib = 1024 * 1024 * 1024
+	if stats.TotalModels != 3 {
+		t.Errorf("Expected 3 models, got %d", stats.TotalModels)
+	}
+	if stats.Families["llama"] != 2 || stats.Families["qwen"] != 1 {
+		t.Errorf("Expected families llama=2 qwen=1, got %v", stats.Families)
+	}
+	if stats.TotalSize != 6*gib || stats.AverageSize != 2*gib {
+		t.Errorf("Expected total 6 GiB and average 2 GiB, got %d and %d", stats.TotalSize, stats.AverageSize)
+	}
+	if stats.SmallestModel.Name != "model1" || stats.LargestModel.Name != "model2" {
+		t.Errorf("Expected smallest model1 and largest model2, got %s and %s", stats.SmallestModel.Name, stats.LargestModel.Name)
+	}
+}
+
+func TestPrintStatsText(t *testing.T) {
+	stats := converter.ComputeStats(converter.OllamaResponse{Models: []converter.OllamaModel{
+		{Name: "model1", Size: 2048, Details: converter.OllamaDetails{Family: "llama"}},
+	}})
+
+	var buf bytes.Buffer
+	err := printStats(&buf, stats, "text")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "Total models: 1\nTotal size: 2.00 KiB\nAverage size: 2.00 KiB\nSmallest model: model1 (2.00 KiB)\nLargest model: model1 (2.00 KiB)\nFamilies:\n  llama: 1\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"dmr-models-convert/pkg/converter"
+
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration and DMR connection without writing output",
+	Long: `Check that the flags are coherent, resolve the DMR servers or files, and
+fetch the models once, reporting the model count. Exits 0 only if every
+check passes, so it can gate a deployment.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return validateConfig(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validateConfig checks the effective configuration and fetches the DMR
+// models once, writing a summary to w
+func validateConfig(w io.Writer) error {
+	err := validateFields(outputFields)
+	if err != nil {
+		return fmt.Errorf("checking --fields: %w", err)
+	}
+
+	_, err = encodeOllamaResponse(converter.OllamaResponse{}, outputFormat)
+	if err != nil {
+		return fmt.Errorf("checking --format: %w", err)
+	}
+
+	err = converter.ValidateFieldPaths(fieldPaths)
+	if err != nil {
+		return fmt.Errorf("checking --field-path: %w", err)
+	}
+
+	if len(dmrFiles) > 0 {
+		fmt.Fprintf(w, "DMR files: %s\n", strings.Join(dmrFiles, ", "))
+	} else {
+		urls := expandDMRURLs(dmrURLs)
+		for _, dmrURL := range urls {
+			err = validateDMRURL(dmrURL)
+			if err != nil {
+				return fmt.Errorf("checking --dmr: %w", err)
+			}
+		}
+		fmt.Fprintf(w, "DMR servers: %s\n", strings.Join(urls, ", "))
+	}
+
+	conv := newConverter()
+	defer conv.Close()
+
+	dmrModels, err := loadDMRModels(conv)
+	if err != nil {
+		return fmt.Errorf("fetching DMR models: %w", err)
+	}
+	fmt.Fprintf(w, "Found %d models\n", len(dmrModels))
+	fmt.Fprintln(w, "Configuration OK")
+
+	return nil
+}
+
+// validateDMRURL checks that a DMR URL is an absolute http or https URL
+func validateDMRURL(dmrURL string) error {
+	u, err := url.Parse(dmrURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must be an http or https URL", dmrURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", dmrURL)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": "sha256:aaa", "tags": ["model1"]}, {"id": "sha256:bbb", "tags": ["model2"]}]`))
+	}))
+	defer server.Close()
+
+	savedURLs := dmrURLs
+	dmrURLs = []string{server.URL}
+	defer func() { dmrURLs = savedURLs }()
+
+	var buf bytes.Buffer
+	err := validateConfig(&buf)
+	if err != nil {
+		t.Fatalf("Expected valid configuration, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Found 2 models") || !strings.Contains(buf.String(), "Configuration OK") {
+		t.Errorf("Expected model count and OK summary, got %q", buf.String())
+	}
+}
+
+func TestValidateConfigBadDMRURL(t *testing.T) {
+	// Grab a free port, then close it so nothing is listening
+	server := httptest.NewServer(http.NotFoundHandler())
+	closedURL := server.URL
+	server.Close()
+
+	savedURLs := dmrURLs
+	defer func() { dmrURLs = savedURLs }()
+
+	tests := map[string]string{
+		"malformed":   "localhost:12434/models",
+		"unreachable": closedURL,
+	}
+
+	for name, dmrURL := range tests {
+		t.Run(name, func(t *testing.T) {
+			dmrURLs = []string{dmrURL}
+
+			err := validateConfig(&bytes.Buffer{})
+			if err == nil {
+				t.Fatal("Expected error for bad DMR URL, got nil")
+			}
+		})
+	}
+}
+
+func TestValidateConfigBadFlags(t *testing.T) {
+	defer func() { outputFields, outputFormat = nil, "json" }()
+
+	outputFormat = "xml"
+	err := validateConfig(&bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "--format") {
+		t.Errorf("Expected --format error, got %v", err)
+	}
+}
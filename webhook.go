@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"dmr-models-convert/pkg/converter"
+)
+
+// webhookOutput POSTs the converted response in the output format to a URL,
+// retrying failed deliveries
+type webhookOutput struct {
+	url        string
+	format     string
+	headers    map[string]string
+	client     *http.Client
+	retries    int
+	retryDelay time.Duration
+}
+
+func newWebhookOutput() webhookOutput {
+	return webhookOutput{
+		url:        webhook,
+		format:     outputFormat,
+		headers:    webhookHeaders,
+		client:     &http.Client{Timeout: webhookTimeout},
+		retries:    webhookRetries,
+		retryDelay: retryDelay,
+	}
+}
+
+func (w webhookOutput) Write(response converter.OllamaResponse) error {
+	data, err := encodeOllamaResponse(response, w.format)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 {
+			fmt.Fprintf(os.Stderr, "Webhook failed: %v, retrying in %s (attempt %d of %d)\n", lastErr, w.retryDelay, attempt+1, w.retries+1)
+			time.Sleep(w.retryDelay)
+		}
+
+		status, err := w.post(data)
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Webhook %s responded with status: %s\n", w.url, status)
+			return nil
+		}
+		lastErr = err
+	}
+
+	return outputError{fmt.Errorf("posting to webhook: %w", lastErr)}
+}
+
+// formatContentTypes maps each --format to the Content-Type of its body
+var formatContentTypes = map[string]string{
+	"json":  "application/json",
+	"names": "text/plain; charset=utf-8",
+	"csv":   "text/csv; charset=utf-8",
+}
+
+// post sends one delivery attempt and returns the response status
+func (w webhookOutput) post(data []byte) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", formatContentTypes[w.format])
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.Status, fmt.Errorf("webhook returned status: %s", resp.Status)
+	}
+
+	return resp.Status, nil
+}
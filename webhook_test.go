@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"dmr-models-convert/pkg/converter"
+)
+
+func TestWebhookOutput(t *testing.T) {
+	var received converter.OllamaResponse
+	var authHeader, contentType string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// Fail the first delivery to exercise retries
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		authHeader = r.Header.Get("Authorization")
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	out := webhookOutput{
+		url:     server.URL,
+		format:  "json",
+		headers: map[string]string{"Authorization": "Bearer secret"},
+		client:  server.Client(),
+		retries: 2,
+	}
+
+	response := converter.OllamaResponse{Models: []converter.OllamaModel{{Name: "model1", Digest: "abc"}}}
+	err := out.Write(response)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+
+	if len(received.Models) != 1 || received.Models[0].Name != "model1" {
+		t.Errorf("Expected model1 in webhook body, got %+v", received)
+	}
+
+	if authHeader != "Bearer secret" {
+		t.Errorf("Expected custom header, got '%s'", authHeader)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Expected JSON content type, got '%s'", contentType)
+	}
+}
+
+func TestWebhookOutputGivesUp(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	out := webhookOutput{url: server.URL, format: "json", client: &http.Client{Timeout: time.Second}, retries: 1}
+	err := out.Write(converter.OllamaResponse{})
+	if err == nil {
+		t.Fatal("Expected error after retries, got nil")
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 attempts, got %d", requests)
+	}
+}
+
+func TestWebhookOutputFormat(t *testing.T) {
+	var body, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	out := webhookOutput{url: server.URL, format: "names", client: server.Client()}
+	response := converter.OllamaResponse{Models: []converter.OllamaModel{{Name: "model1"}, {Name: "model2"}}}
+	if err := out.Write(response); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if body != "model1\nmodel2\n" {
+		t.Errorf("Expected one name per line, got %q", body)
+	}
+	if contentType != "text/plain; charset=utf-8" {
+		t.Errorf("Expected plain text content type, got '%s'", contentType)
+	}
+}